@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"image"
 	"image/color"
+	"math"
 	"testing"
 
 	"golang.org/x/image/vector"
@@ -72,6 +73,42 @@ func BenchmarkRasterizerMethodB(b *testing.B) {
 			clip := rect.Rect{LLx: 0, LLy: 0, URx: float64(size), URy: float64(size)}
 			r := NewRasterizer(clip)
 			r.smallPathThreshold = 0 // Force method B
+			r.sparseCellRatio = 0
+
+			dst := image.NewAlpha(image.Rect(0, 0, size, size))
+
+			center := float64(size) / 2
+			outerR := float64(size) * 0.45
+			innerR := float64(size) * 0.30
+
+			oPath := makeOPath(center, center, outerR, innerR)
+			oPathIter := oPath.Iter()
+
+			b.ResetTimer()
+			b.ReportAllocs()
+
+			for b.Loop() {
+				r.FillEvenOdd(oPathIter, func(y, xMin int, coverage []float32) {
+					row := dst.Pix[y*dst.Stride+xMin:]
+					for i, c := range coverage {
+						row[i] = uint8(c * 255)
+					}
+				})
+			}
+		})
+	}
+}
+
+// BenchmarkRasterizerMethodC benchmarks using fillSparsePath (sparse cells).
+func BenchmarkRasterizerMethodC(b *testing.B) {
+	sizes := []int{20, 200, 2000}
+
+	for _, size := range sizes {
+		b.Run(fmt.Sprintf("%dx%d", size, size), func(b *testing.B) {
+			clip := rect.Rect{LLx: 0, LLy: 0, URx: float64(size), URy: float64(size)}
+			r := NewRasterizer(clip)
+			r.smallPathThreshold = 0 // Force method C
+			r.sparseCellRatio = math.Inf(1)
 
 			dst := image.NewAlpha(image.Rect(0, 0, size, size))
 
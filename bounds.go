@@ -0,0 +1,108 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package raster
+
+import (
+	"seehuhn.de/go/geom/path"
+	"seehuhn.de/go/geom/rect"
+	"seehuhn.de/go/geom/vec"
+)
+
+// FillBounds returns the bounding box, in device space, of the area
+// painted when filling p. Curves are flattened using Flatness, as for
+// filling. The result is not rounded to pixels and not restricted to Clip.
+// If nothing would be painted, the zero rectangle is returned.
+func (r *Rasterizer) FillBounds(p path.Path) rect.Rect {
+	r.collectPathEdges(p, false)
+	if len(r.edges) == 0 {
+		return rect.Rect{}
+	}
+	return rect.Rect{
+		LLx: r.edgeDevXMin,
+		LLy: r.edgeDevYMin,
+		URx: r.edgeDevXMax,
+		URy: r.edgeDevYMax,
+	}
+}
+
+// FillUserBounds is like FillBounds, but returns the bounding box in user
+// space.
+func (r *Rasterizer) FillUserBounds(p path.Path) rect.Rect {
+	r.collectPathEdges(p, false)
+
+	inv := r.CTM.Inv()
+	var b pointBounds
+	for i := range r.edges {
+		e := &r.edges[i]
+		x0, y0 := inv.Apply(e.x0, e.y0)
+		x1, y1 := inv.Apply(e.x1, e.y1)
+		b.add(vec.Vec2{X: x0, Y: y0})
+		b.add(vec.Vec2{X: x1, Y: y1})
+	}
+	return b.Rect
+}
+
+// StrokeBounds returns the bounding box, in device space, of the area
+// painted by Stroke. Caps, joins, miters and dashes are included. The
+// result is not rounded to pixels and not restricted to Clip. If nothing
+// would be painted, the zero rectangle is returned.
+func (r *Rasterizer) StrokeBounds(p path.Path) rect.Rect {
+	r.buildStrokeOutlines(p)
+	return r.strokeOutlineBounds(r.toDevice)
+}
+
+// StrokeUserBounds is like StrokeBounds, but returns the bounding box in
+// user space.
+func (r *Rasterizer) StrokeUserBounds(p path.Path) rect.Rect {
+	r.buildStrokeOutlines(p)
+	return r.strokeOutlineBounds(func(v vec.Vec2) vec.Vec2 { return v })
+}
+
+// strokeOutlineBounds returns the bounding box of the polygons in
+// r.stroke, after applying the transformation f to all points.
+func (r *Rasterizer) strokeOutlineBounds(f func(vec.Vec2) vec.Vec2) rect.Rect {
+	var b pointBounds
+	for i, start := range r.strokeOffsets {
+		end := len(r.stroke)
+		if i+1 < len(r.strokeOffsets) {
+			end = r.strokeOffsets[i+1]
+		}
+		poly := r.stroke[start:end]
+		if len(poly) < 2 {
+			continue
+		}
+		for _, v := range poly {
+			b.add(f(v))
+		}
+	}
+	return b.Rect
+}
+
+// pointBounds accumulates the bounding box of a set of points.
+type pointBounds struct {
+	rect.Rect
+	nonEmpty bool
+}
+
+func (b *pointBounds) add(v vec.Vec2) {
+	if !b.nonEmpty {
+		b.Rect = rect.Rect{LLx: v.X, LLy: v.Y, URx: v.X, URy: v.Y}
+		b.nonEmpty = true
+		return
+	}
+	b.Rect.Add(v.X, v.Y)
+}
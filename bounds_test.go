@@ -0,0 +1,105 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package raster
+
+import (
+	"math"
+	"testing"
+
+	"seehuhn.de/go/geom/matrix"
+	"seehuhn.de/go/geom/path"
+	"seehuhn.de/go/geom/rect"
+	"seehuhn.de/go/geom/vec"
+	"seehuhn.de/go/pdf/graphics"
+)
+
+// rectClose reports whether all coordinates of a and b agree to within
+// 1e-9.
+func rectClose(a, b rect.Rect) bool {
+	return math.Abs(a.LLx-b.LLx) < 1e-9 && math.Abs(a.LLy-b.LLy) < 1e-9 &&
+		math.Abs(a.URx-b.URx) < 1e-9 && math.Abs(a.URy-b.URy) < 1e-9
+}
+
+func TestFillBounds(t *testing.T) {
+	p := (&path.Data{}).
+		MoveTo(vec.Vec2{X: 1, Y: 2}).
+		LineTo(vec.Vec2{X: 7, Y: 3}).
+		LineTo(vec.Vec2{X: 4, Y: 9}).
+		MoveTo(vec.Vec2{X: 100, Y: 100}) // paints nothing
+	r := NewRasterizer(rect.Rect{URx: 5, URy: 5})
+	r.CTM = matrix.Scale(2, 3).Translate(10, 0)
+
+	user := rect.Rect{LLx: 1, LLy: 2, URx: 7, URy: 9}
+	if got := r.FillUserBounds(p.Iter()); !rectClose(got, user) {
+		t.Errorf("user space: got %v, want %v", got, user)
+	}
+	device := rect.Rect{LLx: 12, LLy: 6, URx: 24, URy: 27}
+	if got := r.FillBounds(p.Iter()); !rectClose(got, device) {
+		t.Errorf("device space: got %v, want %v", got, device)
+	}
+
+	empty := (&path.Data{}).MoveTo(vec.Vec2{X: 1, Y: 1}).LineTo(vec.Vec2{X: 5, Y: 1})
+	if got := r.FillBounds(empty.Iter()); !got.IsZero() {
+		t.Errorf("empty fill: got %v", got)
+	}
+}
+
+func TestStrokeBounds(t *testing.T) {
+	line := (&path.Data{}).MoveTo(vec.Vec2{X: 10, Y: 10}).LineTo(vec.Vec2{X: 30, Y: 10})
+	r := NewRasterizer(rect.Rect{URx: 50, URy: 50})
+	r.Width = 4
+
+	want := rect.Rect{LLx: 10, LLy: 8, URx: 30, URy: 12}
+	if got := r.StrokeUserBounds(line.Iter()); !rectClose(got, want) {
+		t.Errorf("butt cap: got %v, want %v", got, want)
+	}
+
+	r.Cap = graphics.LineCapSquare
+	want = rect.Rect{LLx: 8, LLy: 8, URx: 32, URy: 12}
+	if got := r.StrokeUserBounds(line.Iter()); !rectClose(got, want) {
+		t.Errorf("square cap: got %v, want %v", got, want)
+	}
+
+	r.Cap = graphics.LineCapButt
+	r.Dash = []float64{5, 5}
+	want = rect.Rect{LLx: 10, LLy: 8, URx: 25, URy: 12}
+	if got := r.StrokeUserBounds(line.Iter()); !rectClose(got, want) {
+		t.Errorf("dashed: got %v, want %v", got, want)
+	}
+	r.Dash = nil
+
+	// The miter of a right angle extends by Width/2 in both directions.
+	corner := (&path.Data{}).
+		MoveTo(vec.Vec2{X: 10, Y: 10}).
+		LineTo(vec.Vec2{X: 30, Y: 10}).
+		LineTo(vec.Vec2{X: 30, Y: 30})
+	want = rect.Rect{LLx: 10, LLy: 8, URx: 32, URy: 30}
+	if got := r.StrokeUserBounds(corner.Iter()); !rectClose(got, want) {
+		t.Errorf("miter: got %v, want %v", got, want)
+	}
+
+	r.CTM = matrix.Translate(-5, 1)
+	want = rect.Rect{LLx: 5, LLy: 9, URx: 27, URy: 31}
+	if got := r.StrokeBounds(corner.Iter()); !rectClose(got, want) {
+		t.Errorf("device space: got %v, want %v", got, want)
+	}
+
+	point := (&path.Data{}).MoveTo(vec.Vec2{X: 10, Y: 10}).Close()
+	if got := r.StrokeBounds(point.Iter()); !got.IsZero() {
+		t.Errorf("butt-capped point: got %v", got)
+	}
+}
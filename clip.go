@@ -0,0 +1,178 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package raster
+
+import (
+	"slices"
+
+	"seehuhn.de/go/geom/path"
+)
+
+// clipMask holds the coverage of one level of the clip stack.
+//
+// Each level stores the intersection of its own clip path with all levels
+// below it, so only the topmost level needs to be consulted when emitting
+// coverage.
+type clipMask struct {
+	// xMin, xMax, yMin, yMax is the region covered by the mask, in device
+	// pixels. Outside this region the mask is zero. An empty region
+	// (xMin >= xMax or yMin >= yMax) clips away everything.
+	xMin, xMax int
+	yMin, yMax int
+
+	// coverage holds the mask values in row-major order,
+	// (xMax-xMin)*(yMax-yMin) entries.
+	coverage []float32
+}
+
+// PushClipPath intersects the clipping region with the interior of p, as
+// determined by rule. The path is given in user space and is transformed
+// by the current CTM. Clip paths nest: all subsequent fills and strokes
+// are restricted to the intersection of Clip and every clip path pushed so
+// far, until the path is removed again by PopClip.
+//
+// Anti-aliased edges of the clip path are taken into account by
+// multiplying the coverage of each painted pixel by the coverage of the
+// clip path.
+//
+// If rendering the clip path is aborted, an empty clip path is pushed
+// instead, so that everything is clipped and each call to PushClipPath
+// can still be matched by a call to PopClip.
+func (r *Rasterizer) PushClipPath(p path.Path, rule FillRule) {
+	// The clip path is rasterised while the previous level is still
+	// active, so that the new mask is the intersection of both.
+	xMin, xMax, yMin, yMax, ok := r.collectPathEdges(p, true)
+	if r.aborted() || !ok {
+		r.pushEmptyClip()
+		return
+	}
+	width := xMax - xMin
+	size := width * (yMax - yMin)
+	if !r.bufferFits(4 * size) {
+		r.abort("MaxBufferBytes", r.limits.MaxBufferBytes)
+		r.pushEmptyClip()
+		return
+	}
+
+	if r.clipDepth == len(r.clipStack) {
+		r.clipStack = append(r.clipStack, clipMask{})
+	}
+	m := &r.clipStack[r.clipDepth]
+
+	m.xMin, m.xMax, m.yMin, m.yMax = xMin, xMax, yMin, yMax
+	m.coverage = slices.Grow(m.coverage[:0], size)[:size]
+	clear(m.coverage)
+
+	emit := func(y, x int, coverage []float32) {
+		copy(m.coverage[(y-yMin)*width+(x-xMin):], coverage)
+	}
+	r.fillEdges(xMin, xMax, yMin, yMax, rule, emit)
+	if r.aborted() {
+		r.pushEmptyClip()
+		return
+	}
+
+	r.clipDepth++
+}
+
+// pushEmptyClip pushes a clip path which clips everything.
+func (r *Rasterizer) pushEmptyClip() {
+	if r.clipDepth == len(r.clipStack) {
+		r.clipStack = append(r.clipStack, clipMask{})
+	}
+	m := &r.clipStack[r.clipDepth]
+	m.xMin, m.xMax, m.yMin, m.yMax = 0, 0, 0, 0
+	m.coverage = m.coverage[:0]
+	r.clipDepth++
+}
+
+// PopClip removes the clip path most recently added by PushClipPath.
+// If no clip path is active, PopClip does nothing.
+//
+// Clip paths which are part of a graphics state saved by Save can only be
+// removed by the matching call to Restore; PopClip panics if it is used to
+// remove one of them.
+func (r *Rasterizer) PopClip() {
+	if r.saveDepth > 0 && r.clipDepth > 0 && r.clipDepth <= r.saveStack[r.saveDepth-1].clipDepth {
+		panic("raster: PopClip removes a clip path saved by Save")
+	}
+	if r.clipDepth > 0 {
+		r.clipDepth--
+	}
+}
+
+// ClipDepth returns the number of clip paths currently active.
+func (r *Rasterizer) ClipDepth() int {
+	return r.clipDepth
+}
+
+// clipBounds returns the integer pixel region which can receive non-zero
+// coverage: Clip, intersected with the region of the active clip path.
+func (r *Rasterizer) clipBounds() (xMin, xMax, yMin, yMax int) {
+	xMin = int(r.Clip.LLx)
+	xMax = int(r.Clip.URx)
+	yMin = int(r.Clip.LLy)
+	yMax = int(r.Clip.URy)
+
+	if r.clipDepth > 0 {
+		m := &r.clipStack[r.clipDepth-1]
+		xMin = max(xMin, m.xMin)
+		xMax = min(xMax, m.xMax)
+		yMin = max(yMin, m.yMin)
+		yMax = min(yMax, m.yMax)
+	}
+	return xMin, xMax, yMin, yMax
+}
+
+// emitRow applies the active clip path to a row of coverage values and
+// passes the non-zero portion to emit. The row must lie within the region
+// returned by clipBounds. The coverage slice is modified in place.
+func (r *Rasterizer) emitRow(y, xMin int, coverage []float32, emit func(y, xMin int, coverage []float32)) {
+	trimmed, offset := trimZeros(coverage)
+	if trimmed == nil {
+		return
+	}
+	xMin += offset
+
+	if mask := r.maskRow(y, xMin, len(trimmed)); mask != nil {
+		for i, c := range mask {
+			trimmed[i] *= c
+		}
+
+		var offset int
+		trimmed, offset = trimZeros(trimmed)
+		if trimmed == nil {
+			return
+		}
+		xMin += offset
+	}
+
+	emit(y, xMin, trimmed)
+}
+
+// maskRow returns the coverage of the active clip path for pixels
+// xMin, …, xMin+n-1 of row y, or nil if no clip path is active. The pixels
+// must lie within the region returned by clipBounds.
+func (r *Rasterizer) maskRow(y, xMin, n int) []float32 {
+	if r.clipDepth == 0 {
+		return nil
+	}
+	m := &r.clipStack[r.clipDepth-1]
+	width := m.xMax - m.xMin
+	start := (y-m.yMin)*width + (xMin - m.xMin)
+	return m.coverage[start : start+n]
+}
@@ -0,0 +1,167 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package raster
+
+import (
+	"math"
+	"testing"
+
+	"seehuhn.de/go/geom/path"
+	"seehuhn.de/go/geom/rect"
+	"seehuhn.de/go/geom/vec"
+)
+
+// rectPath returns a closed rectangular path.
+func rectPath(x0, y0, x1, y1 float64) *path.Data {
+	return (&path.Data{}).
+		MoveTo(vec.Vec2{X: x0, Y: y0}).
+		LineTo(vec.Vec2{X: x1, Y: y0}).
+		LineTo(vec.Vec2{X: x1, Y: y1}).
+		LineTo(vec.Vec2{X: x0, Y: y1}).
+		Close()
+}
+
+// renderCoverage fills p with the nonzero rule and returns the coverage
+// as a w×h row-major buffer.
+func renderCoverage(r *Rasterizer, p *path.Data, w, h int) []float32 {
+	buf := make([]float32, w*h)
+	r.FillNonZero(p.Iter(), func(y, xMin int, coverage []float32) {
+		copy(buf[y*w+xMin:], coverage)
+	})
+	return buf
+}
+
+// renderStroke strokes p and returns the coverage as a w×h buffer.
+func renderStroke(r *Rasterizer, p *path.Data, w, h int) []float32 {
+	buf := make([]float32, w*h)
+	r.Stroke(p.Iter(), func(y, xMin int, coverage []float32) {
+		copy(buf[y*w+xMin:], coverage)
+	})
+	return buf
+}
+
+func TestClipPath(t *testing.T) {
+	for _, threshold := range []int{1 << 30, 0} {
+		r := NewRasterizer(rect.Rect{URx: 10, URy: 10})
+		r.smallPathThreshold = threshold
+
+		r.PushClipPath(rectPath(2, 3, 6, 8).Iter(), NonZero)
+		buf := renderCoverage(r, rectPath(0, 0, 10, 10), 10, 10)
+
+		for y := range 10 {
+			for x := range 10 {
+				var want float32
+				if x >= 2 && x < 6 && y >= 3 && y < 8 {
+					want = 1
+				}
+				if got := buf[y*10+x]; got != want {
+					t.Errorf("threshold %d: pixel (%d,%d) = %g, want %g",
+						threshold, x, y, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestClipPathAntiAliased(t *testing.T) {
+	r := NewRasterizer(rect.Rect{URx: 4, URy: 1})
+
+	// clip covers the left half of pixel 1, fill covers the right
+	// quarter of pixel 1
+	r.PushClipPath(rectPath(0, 0, 1.5, 1).Iter(), NonZero)
+	buf := renderCoverage(r, rectPath(1.75, 0, 4, 1), 4, 1)
+
+	want := []float32{0, 0.25 * 0.5, 0, 0}
+	for x, w := range want {
+		if math.Abs(float64(buf[x]-w)) > 1e-6 {
+			t.Errorf("pixel %d: got %g, want %g", x, buf[x], w)
+		}
+	}
+}
+
+func TestClipPathNested(t *testing.T) {
+	r := NewRasterizer(rect.Rect{URx: 10, URy: 1})
+	square := rectPath(0, 0, 10, 1)
+
+	r.PushClipPath(rectPath(2, 0, 8, 1).Iter(), NonZero)
+	r.PushClipPath(rectPath(5, 0, 10, 1).Iter(), NonZero)
+	if r.ClipDepth() != 2 {
+		t.Fatalf("ClipDepth() = %d, want 2", r.ClipDepth())
+	}
+
+	check := func(label string, lo, hi int) {
+		t.Helper()
+		buf := renderCoverage(r, square, 10, 1)
+		for x, c := range buf {
+			var want float32
+			if x >= lo && x < hi {
+				want = 1
+			}
+			if c != want {
+				t.Errorf("%s: pixel %d = %g, want %g", label, x, c, want)
+			}
+		}
+	}
+
+	check("both", 5, 8)
+	r.PopClip()
+	check("outer", 2, 8)
+	r.PopClip()
+	check("none", 0, 10)
+
+	// popping an empty stack is harmless
+	r.PopClip()
+	check("empty", 0, 10)
+}
+
+func TestClipPathEmpty(t *testing.T) {
+	r := NewRasterizer(rect.Rect{URx: 10, URy: 10})
+
+	// a clip path outside the clip rectangle removes everything
+	r.PushClipPath(rectPath(20, 20, 30, 30).Iter(), NonZero)
+
+	r.FillNonZero(rectPath(0, 0, 10, 10).Iter(), func(y, xMin int, coverage []float32) {
+		t.Errorf("unexpected output in row %d", y)
+	})
+	r.Stroke(rectPath(1, 1, 9, 9).Iter(), func(y, xMin int, coverage []float32) {
+		t.Errorf("unexpected stroke output in row %d", y)
+	})
+}
+
+func TestClipPathEvenOdd(t *testing.T) {
+	r := NewRasterizer(rect.Rect{URx: 10, URy: 1})
+
+	// two overlapping rectangles; the overlap is outside for even-odd
+	clip := rectPath(0, 0, 6, 1)
+	clip.MoveTo(vec.Vec2{X: 4, Y: 0}).
+		LineTo(vec.Vec2{X: 10, Y: 0}).
+		LineTo(vec.Vec2{X: 10, Y: 1}).
+		LineTo(vec.Vec2{X: 4, Y: 1}).
+		Close()
+	r.PushClipPath(clip.Iter(), EvenOdd)
+
+	buf := renderCoverage(r, rectPath(0, 0, 10, 1), 10, 1)
+	for x, c := range buf {
+		var want float32 = 1
+		if x == 4 || x == 5 {
+			want = 0
+		}
+		if c != want {
+			t.Errorf("pixel %d = %g, want %g", x, c, want)
+		}
+	}
+}
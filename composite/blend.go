@@ -0,0 +1,405 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package composite
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// BlendMode selects the PDF blend function used to combine source and
+// backdrop colours.
+type BlendMode int
+
+// The PDF blend modes. The first twelve are separable: each colour
+// component is blended independently. The last four are non-separable.
+const (
+	BlendNormal BlendMode = iota
+	BlendMultiply
+	BlendScreen
+	BlendOverlay
+	BlendDarken
+	BlendLighten
+	BlendColorDodge
+	BlendColorBurn
+	BlendHardLight
+	BlendSoftLight
+	BlendDifference
+	BlendExclusion
+	BlendHue
+	BlendSaturation
+	BlendColor
+	BlendLuminosity
+)
+
+var blendModeNames = [...]string{
+	BlendNormal:     "Normal",
+	BlendMultiply:   "Multiply",
+	BlendScreen:     "Screen",
+	BlendOverlay:    "Overlay",
+	BlendDarken:     "Darken",
+	BlendLighten:    "Lighten",
+	BlendColorDodge: "ColorDodge",
+	BlendColorBurn:  "ColorBurn",
+	BlendHardLight:  "HardLight",
+	BlendSoftLight:  "SoftLight",
+	BlendDifference: "Difference",
+	BlendExclusion:  "Exclusion",
+	BlendHue:        "Hue",
+	BlendSaturation: "Saturation",
+	BlendColor:      "Color",
+	BlendLuminosity: "Luminosity",
+}
+
+// String returns the PDF name of the blend mode.
+func (m BlendMode) String() string {
+	if m >= 0 && int(m) < len(blendModeNames) {
+		return blendModeNames[m]
+	}
+	return "BlendMode(?)"
+}
+
+// ParseBlendMode returns the blend mode with the given PDF name. The
+// deprecated name "Compatible" is treated as "Normal".
+func ParseBlendMode(name string) (BlendMode, bool) {
+	if name == "Compatible" {
+		return BlendNormal, true
+	}
+	for m, n := range blendModeNames {
+		if n == name {
+			return BlendMode(m), true
+		}
+	}
+	return BlendNormal, false
+}
+
+// isSeparable reports whether the blend mode acts on each colour component
+// independently.
+func (m BlendMode) isSeparable() bool {
+	return m < BlendHue
+}
+
+// blendPixel composites the premultiplied source colour s with alpha
+// already scaled by coverage and constant alpha, onto the premultiplied
+// backdrop b, using the general PDF compositing formula
+//
+//	αr = αs + αb − αs·αb
+//	αr·Cr = (1−αb)·αs·Cs + (1−αs)·αb·Cb + αs·αb·B(Cb, Cs)
+//
+// where Cs and Cb are non-premultiplied.
+func (m BlendMode) blendPixel(s, b RGBA) RGBA {
+	if s.A <= 0 {
+		return b
+	}
+	if b.A <= 0 {
+		return s
+	}
+
+	cs := [3]float32{s.R / s.A, s.G / s.A, s.B / s.A}
+	cb := [3]float32{b.R / b.A, b.G / b.A, b.B / b.A}
+
+	var f [3]float32
+	if m.isSeparable() {
+		for i := range f {
+			f[i] = m.blendSeparable(cb[i], cs[i])
+		}
+	} else {
+		f = m.blendNonSeparable(cb, cs)
+	}
+
+	sb := s.A * b.A
+	return RGBA{
+		R: s.R*(1-b.A) + b.R*(1-s.A) + sb*f[0],
+		G: s.G*(1-b.A) + b.G*(1-s.A) + sb*f[1],
+		B: s.B*(1-b.A) + b.B*(1-s.A) + sb*f[2],
+		A: s.A + b.A - sb,
+	}
+}
+
+// blendSeparable applies a separable blend function to one non-premultiplied
+// colour component.
+func (m BlendMode) blendSeparable(cb, cs float32) float32 {
+	switch m {
+	case BlendMultiply:
+		return cb * cs
+	case BlendScreen:
+		return cb + cs - cb*cs
+	case BlendOverlay:
+		return hardLight(cs, cb)
+	case BlendDarken:
+		return min(cb, cs)
+	case BlendLighten:
+		return max(cb, cs)
+	case BlendColorDodge:
+		switch {
+		case cb <= 0:
+			return 0
+		case cs >= 1:
+			return 1
+		}
+		return min(1, cb/(1-cs))
+	case BlendColorBurn:
+		switch {
+		case cb >= 1:
+			return 1
+		case cs <= 0:
+			return 0
+		}
+		return 1 - min(1, (1-cb)/cs)
+	case BlendHardLight:
+		return hardLight(cb, cs)
+	case BlendSoftLight:
+		if cs <= 0.5 {
+			return cb - (1-2*cs)*cb*(1-cb)
+		}
+		var d float32
+		if cb <= 0.25 {
+			d = ((16*cb-12)*cb + 4) * cb
+		} else {
+			d = float32(math.Sqrt(float64(cb)))
+		}
+		return cb + (2*cs-1)*(d-cb)
+	case BlendDifference:
+		if cb > cs {
+			return cb - cs
+		}
+		return cs - cb
+	case BlendExclusion:
+		return cb + cs - 2*cb*cs
+	default: // BlendNormal
+		return cs
+	}
+}
+
+// hardLight implements the HardLight blend function.
+func hardLight(cb, cs float32) float32 {
+	if cs <= 0.5 {
+		return cb * 2 * cs
+	}
+	t := 2*cs - 1
+	return cb + t - cb*t
+}
+
+// blendNonSeparable applies one of the non-separable blend functions to
+// non-premultiplied RGB colours.
+func (m BlendMode) blendNonSeparable(cb, cs [3]float32) [3]float32 {
+	switch m {
+	case BlendHue:
+		return setLum(setSat(cs, sat(cb)), lum(cb))
+	case BlendSaturation:
+		return setLum(setSat(cb, sat(cs)), lum(cb))
+	case BlendColor:
+		return setLum(cs, lum(cb))
+	default: // BlendLuminosity
+		return setLum(cb, lum(cs))
+	}
+}
+
+// lum returns the luminosity of a colour, as defined in the PDF
+// specification.
+func lum(c [3]float32) float32 {
+	return 0.3*c[0] + 0.59*c[1] + 0.11*c[2]
+}
+
+func setLum(c [3]float32, l float32) [3]float32 {
+	d := l - lum(c)
+	return clipColor([3]float32{c[0] + d, c[1] + d, c[2] + d})
+}
+
+func clipColor(c [3]float32) [3]float32 {
+	l := lum(c)
+	n := min(c[0], c[1], c[2])
+	x := max(c[0], c[1], c[2])
+	if n < 0 {
+		for i := range c {
+			c[i] = l + (c[i]-l)*l/(l-n)
+		}
+	}
+	if x > 1 {
+		for i := range c {
+			c[i] = l + (c[i]-l)*(1-l)/(x-l)
+		}
+	}
+	return c
+}
+
+func sat(c [3]float32) float32 {
+	return max(c[0], c[1], c[2]) - min(c[0], c[1], c[2])
+}
+
+// setSat returns a colour with the hue of c and saturation s.
+func setSat(c [3]float32, s float32) [3]float32 {
+	// find indices of the maximum, middle and minimum components
+	iMax, iMid, iMin := 0, 1, 2
+	if c[iMax] < c[iMid] {
+		iMax, iMid = iMid, iMax
+	}
+	if c[iMid] < c[iMin] {
+		iMid, iMin = iMin, iMid
+	}
+	if c[iMax] < c[iMid] {
+		iMax, iMid = iMid, iMax
+	}
+
+	var res [3]float32
+	if c[iMax] > c[iMin] {
+		res[iMid] = (c[iMid] - c[iMin]) * s / (c[iMax] - c[iMin])
+		res[iMax] = s
+	}
+	return res
+}
+
+// blendRow composites a row of source colours onto the destination image,
+// for blend modes other than Normal and inside knockout groups. The
+// backdrop is read into buf, blended, and written back.
+//
+// Coverage is the source opacity, including constant alpha and soft masks.
+// If initial is not nil, the row is in a knockout group: the source is
+// composited onto the initial backdrop of the group instead, and the result
+// replaces the previous contents in proportion to the shape, the coverage
+// of the pixel by the rasterizer.
+func blendRow(dst draw.Image, mode BlendMode, y, xMin int, src []RGBA, shape, coverage []float32, buf, initial []RGBA) {
+	loadRow(dst, y, xMin, buf)
+	for i, cov := range coverage {
+		if shape[i] <= 0 {
+			continue
+		}
+		s := src[i]
+		if initial == nil {
+			s.R *= cov
+			s.G *= cov
+			s.B *= cov
+			s.A *= cov
+			buf[i] = mode.blendPixel(s, buf[i])
+			continue
+		}
+
+		opa := cov / shape[i]
+		s.R *= opa
+		s.G *= opa
+		s.B *= opa
+		s.A *= opa
+		buf[i] = lerpRGBA(buf[i], mode.blendPixel(s, initial[i]), shape[i])
+	}
+	storeRow(dst, y, xMin, buf, shape)
+}
+
+// loadRow reads a row of pixels from an image, as premultiplied colours.
+func loadRow(img image.Image, y, xMin int, buf []RGBA) {
+	switch img := img.(type) {
+	case *image.RGBA:
+		row := img.Pix[img.PixOffset(xMin, y):]
+		for i := range buf {
+			p := row[4*i : 4*i+4 : 4*i+4]
+			buf[i] = RGBA{
+				R: float32(p[0]) / 255,
+				G: float32(p[1]) / 255,
+				B: float32(p[2]) / 255,
+				A: float32(p[3]) / 255,
+			}
+		}
+	case *image.NRGBA:
+		row := img.Pix[img.PixOffset(xMin, y):]
+		for i := range buf {
+			p := row[4*i : 4*i+4 : 4*i+4]
+			a := float32(p[3]) / 255
+			buf[i] = RGBA{
+				R: float32(p[0]) / 255 * a,
+				G: float32(p[1]) / 255 * a,
+				B: float32(p[2]) / 255 * a,
+				A: a,
+			}
+		}
+	case *image.Alpha:
+		// Colour is irrelevant for alpha-only images, only the result
+		// alpha is stored.
+		row := img.Pix[img.PixOffset(xMin, y):]
+		for i := range buf {
+			buf[i] = RGBA{A: float32(row[i]) / 255}
+		}
+	case *image.Gray:
+		row := img.Pix[img.PixOffset(xMin, y):]
+		for i := range buf {
+			v := float32(row[i]) / 255
+			buf[i] = RGBA{R: v, G: v, B: v, A: 1}
+		}
+	default:
+		for i := range buf {
+			buf[i] = NewRGBA(img.At(xMin+i, y))
+		}
+	}
+}
+
+// storeRow writes those pixels of a row back to an image which have
+// non-zero coverage.
+func storeRow(img draw.Image, y, xMin int, buf []RGBA, coverage []float32) {
+	switch img := img.(type) {
+	case *image.RGBA:
+		row := img.Pix[img.PixOffset(xMin, y):]
+		for i, c := range buf {
+			if coverage[i] <= 0 {
+				continue
+			}
+			p := row[4*i : 4*i+4 : 4*i+4]
+			p[0] = to8(c.R * 255)
+			p[1] = to8(c.G * 255)
+			p[2] = to8(c.B * 255)
+			p[3] = to8(c.A * 255)
+		}
+	case *image.NRGBA:
+		row := img.Pix[img.PixOffset(xMin, y):]
+		for i, c := range buf {
+			if coverage[i] <= 0 || c.A <= 0 {
+				continue
+			}
+			p := row[4*i : 4*i+4 : 4*i+4]
+			scale := 255 / c.A
+			p[0] = to8(c.R * scale)
+			p[1] = to8(c.G * scale)
+			p[2] = to8(c.B * scale)
+			p[3] = to8(c.A * 255)
+		}
+	case *image.Alpha:
+		row := img.Pix[img.PixOffset(xMin, y):]
+		for i, c := range buf {
+			if coverage[i] > 0 {
+				row[i] = to8(c.A * 255)
+			}
+		}
+	case *image.Gray:
+		row := img.Pix[img.PixOffset(xMin, y):]
+		for i, c := range buf {
+			if coverage[i] > 0 {
+				row[i] = to8((0.299*c.R + 0.587*c.G + 0.114*c.B) * 255)
+			}
+		}
+	default:
+		for i, c := range buf {
+			if coverage[i] <= 0 {
+				continue
+			}
+			img.Set(xMin+i, y, color.RGBA64{
+				R: to16(c.R),
+				G: to16(c.G),
+				B: to16(c.B),
+				A: to16(c.A),
+			})
+		}
+	}
+}
@@ -0,0 +1,280 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package composite
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"seehuhn.de/go/geom/path"
+	"seehuhn.de/go/geom/rect"
+	"seehuhn.de/go/geom/vec"
+
+	"seehuhn.de/go/raster"
+	"seehuhn.de/go/raster/testcases"
+)
+
+func TestParseBlendMode(t *testing.T) {
+	for m := BlendNormal; m <= BlendLuminosity; m++ {
+		got, ok := ParseBlendMode(m.String())
+		if !ok || got != m {
+			t.Errorf("ParseBlendMode(%q) = %v, %t", m.String(), got, ok)
+		}
+	}
+	if m, ok := ParseBlendMode("Compatible"); !ok || m != BlendNormal {
+		t.Errorf("Compatible: got %v, %t", m, ok)
+	}
+	if _, ok := ParseBlendMode("Foo"); ok {
+		t.Errorf("unknown name accepted")
+	}
+}
+
+func TestBlendSeparable(t *testing.T) {
+	// values for backdrop cb = 0.25 and source cs = 0.75, computed from the
+	// formulas in the PDF specification
+	cases := []struct {
+		mode BlendMode
+		want float32
+	}{
+		{BlendNormal, 0.75},
+		{BlendMultiply, 0.1875},
+		{BlendScreen, 0.8125},
+		{BlendOverlay, 0.375},
+		{BlendDarken, 0.25},
+		{BlendLighten, 0.75},
+		{BlendColorDodge, 1},
+		{BlendColorBurn, 0},
+		{BlendHardLight, 0.625},
+		{BlendSoftLight, 0.375},
+		{BlendDifference, 0.5},
+		{BlendExclusion, 0.625},
+	}
+	for _, tc := range cases {
+		got := tc.mode.blendSeparable(0.25, 0.75)
+		if !closeTo(got, tc.want) {
+			t.Errorf("%v: got %g, want %g", tc.mode, got, tc.want)
+		}
+	}
+
+	// the other branches of the piecewise defined functions
+	if got := BlendColorDodge.blendSeparable(0.25, 0.5); !closeTo(got, 0.5) {
+		t.Errorf("ColorDodge: got %g, want 0.5", got)
+	}
+	if got := BlendColorBurn.blendSeparable(0.75, 0.5); !closeTo(got, 0.5) {
+		t.Errorf("ColorBurn: got %g, want 0.5", got)
+	}
+	if got := BlendSoftLight.blendSeparable(0.64, 1); !closeTo(got, 0.8) {
+		t.Errorf("SoftLight: got %g, want 0.8", got)
+	}
+}
+
+func TestBlendNonSeparable(t *testing.T) {
+	red := [3]float32{1, 0, 0}
+	gray := [3]float32{0.5, 0.5, 0.5}
+
+	// Luminosity of a gray source keeps the backdrop hue.
+	got := BlendLuminosity.blendNonSeparable(red, gray)
+	if l := lum(got); !closeTo(l, 0.5) {
+		t.Errorf("Luminosity: lum = %g, want 0.5", l)
+	}
+	if !(got[0] > got[1] && closeTo(got[1], got[2])) {
+		t.Errorf("Luminosity: hue changed, got %v", got)
+	}
+
+	// Hue and Saturation take the saturation of a gray backdrop.
+	for _, m := range []BlendMode{BlendHue, BlendSaturation} {
+		got := m.blendNonSeparable(gray, red)
+		if !closeTo(got[0], 0.5) || !closeTo(got[1], 0.5) || !closeTo(got[2], 0.5) {
+			t.Errorf("%v: got %v, want gray", m, got)
+		}
+	}
+
+	// Color takes hue and saturation from the source.
+	got = BlendColor.blendNonSeparable(gray, red)
+	if l := lum(got); !closeTo(l, 0.5) {
+		t.Errorf("Color: lum = %g, want 0.5", l)
+	}
+	if !(got[0] > got[1] && closeTo(got[1], got[2])) {
+		t.Errorf("Color: got %v", got)
+	}
+}
+
+func TestCompositorBlend(t *testing.T) {
+	dst := image.NewRGBA(image.Rect(0, 0, 3, 1))
+	for i := range 3 {
+		dst.SetRGBA(i, 0, color.RGBA{R: 255, G: 128, B: 0, A: 255})
+	}
+
+	c := New(dst, color.RGBA{R: 128, G: 128, B: 128, A: 255})
+	c.Blend = BlendMultiply
+	c.Emit(0, 0, []float32{1, 0.5, 0})
+
+	want := []color.RGBA{
+		{R: 128, G: 64, B: 0, A: 255},
+		{R: 192, G: 96, B: 0, A: 255},
+		{R: 255, G: 128, B: 0, A: 255},
+	}
+	for i, w := range want {
+		got := dst.RGBAAt(i, 0)
+		if !close8(got, w) {
+			t.Errorf("pixel %d: got %v, want %v", i, got, w)
+		}
+	}
+
+	// Blending onto a transparent backdrop is the same as Normal.
+	dst = image.NewRGBA(image.Rect(0, 0, 1, 1))
+	c.Dst = dst
+	c.Emit(0, 0, []float32{1})
+	if got := dst.RGBAAt(0, 0); !close8(got, color.RGBA{128, 128, 128, 255}) {
+		t.Errorf("transparent backdrop: got %v", got)
+	}
+}
+
+func TestCompositorAlpha(t *testing.T) {
+	for _, mode := range []BlendMode{BlendNormal, BlendScreen} {
+		dst := image.NewRGBA(image.Rect(0, 0, 2, 1))
+		c := New(dst, color.White)
+		c.Blend = mode
+		c.Alpha = 0.5
+		coverage := []float32{1, 0.5}
+		c.Emit(0, 0, coverage)
+
+		if coverage[0] != 1 || coverage[1] != 0.5 {
+			t.Errorf("%v: coverage modified", mode)
+		}
+		want := []color.RGBA{
+			{R: 128, G: 128, B: 128, A: 128},
+			{R: 64, G: 64, B: 64, A: 64},
+		}
+		for i, w := range want {
+			if got := dst.RGBAAt(i, 0); !close8(got, w) {
+				t.Errorf("%v: pixel %d: got %v, want %v", mode, i, got, w)
+			}
+		}
+	}
+}
+
+// TestBlendFastPaths checks that all destination image types give the same
+// result for the blend modes.
+func TestBlendFastPaths(t *testing.T) {
+	col := color.NRGBA{R: 40, G: 200, B: 90, A: 200}
+	back := color.NRGBA{R: 220, G: 30, B: 120, A: 255}
+	coverage := []float32{1, 0.75, 0.25, 0}
+
+	for m := BlendMultiply; m <= BlendLuminosity; m++ {
+		fast := image.NewRGBA(image.Rect(0, 0, 4, 1))
+		nrgba := image.NewNRGBA(image.Rect(0, 0, 4, 1))
+		generic := image.NewRGBA64(image.Rect(0, 0, 4, 1))
+		for _, img := range []draw.Image{fast, nrgba, generic} {
+			for x := range 4 {
+				img.Set(x, 0, back)
+			}
+			c := New(img, col)
+			c.Blend = m
+			c.Emit(0, 0, coverage)
+		}
+		for x := range 4 {
+			a := color.RGBAModel.Convert(fast.At(x, 0)).(color.RGBA)
+			b := color.RGBAModel.Convert(nrgba.At(x, 0)).(color.RGBA)
+			g := color.RGBAModel.Convert(generic.At(x, 0)).(color.RGBA)
+			if !close8(a, b) || !close8(a, g) {
+				t.Errorf("%v: pixel %d: RGBA %v, NRGBA %v, generic %v", m, x, a, b, g)
+			}
+		}
+	}
+}
+
+// TestBlendReference compares the blend modes with reference renderings
+// made by Ghostscript, see testcases/genpdf. Ghostscript blends in 8-bit
+// arithmetic, so the values may differ by a few units.
+func TestBlendReference(t *testing.T) {
+	w, h := testcases.BlendSize()
+	for _, name := range testcases.BlendModes {
+		mode, ok := ParseBlendMode(name)
+		if !ok {
+			t.Fatalf("unknown blend mode %q", name)
+		}
+
+		ref, err := loadPNG(filepath.Join("..", "testdata", "reference", testcases.BlendFileName(name)+".png"))
+		if errors.Is(err, fs.ErrNotExist) {
+			t.Skip("reference images missing; run go generate in the module root (needs Ghostscript)")
+		} else if err != nil {
+			t.Fatalf("loading reference: %v", err)
+		}
+
+		dst := image.NewRGBA(image.Rect(0, 0, w, h))
+		for x := range w {
+			col := testcases.BlendBackdrops[x/testcases.BlendCell]
+			draw.Draw(dst, image.Rect(x, 0, x+1, h), image.NewUniform(col), image.Point{}, draw.Src)
+		}
+		r := raster.NewRasterizer(rect.Rect{URx: float64(w), URy: float64(h)})
+		c := New(dst, color.Black)
+		c.Blend = mode
+		c.Alpha = testcases.BlendAlpha
+		for i, col := range testcases.BlendSources {
+			b := testcases.BlendBand(i)
+			p := (&path.Data{}).
+				MoveTo(vec.Vec2{X: b.LLx, Y: b.LLy}).
+				LineTo(vec.Vec2{X: b.URx, Y: b.LLy}).
+				LineTo(vec.Vec2{X: b.URx, Y: b.URy}).
+				LineTo(vec.Vec2{X: b.LLx, Y: b.URy}).
+				Close()
+			c.SetColor(col)
+			r.FillNonZero(p.Iter(), c.Emit)
+		}
+
+		for y := range h {
+			for x := range w {
+				want := color.RGBAModel.Convert(ref.At(x, y)).(color.RGBA)
+				if got := dst.RGBAAt(x, y); !closeN(got, want, 3) {
+					t.Errorf("%s: pixel (%d,%d) = %v, want %v", name, x, y, got, want)
+				}
+			}
+		}
+	}
+}
+
+func loadPNG(fname string) (img image.Image, err error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	return png.Decode(f)
+}
+
+// close8 reports whether two colours differ by at most 2 in each component.
+func close8(a, b color.RGBA) bool {
+	return closeN(a, b, 2)
+}
+
+// closeN reports whether two colours differ by at most n in each component.
+func closeN(a, b color.RGBA, n int) bool {
+	d := func(x, y uint8) bool { return int(x)-int(y) <= n && int(y)-int(x) <= n }
+	return d(a.R, b.R) && d(a.G, b.G) && d(a.B, b.B) && d(a.A, b.A)
+}
@@ -0,0 +1,335 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package composite paints the coverage values produced by a
+// raster.Rasterizer into images.
+//
+// A Compositor provides an Emit method which can be passed directly as the
+// emit callback of FillNonZero, FillEvenOdd and Stroke:
+//
+//	c := composite.New(img, color.Black)
+//	r.FillNonZero(p, c.Emit)
+//
+// The colour of each pixel is supplied by a Paint. Besides solid colours,
+// the package provides linear, radial and sweep gradients, transformed
+// images, and tiling patterns. Constant alpha, soft masks, the PDF blend
+// modes and transparency groups are handled by the Compositor.
+package composite
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// Compositor blends a Paint into a destination image, using the Porter-Duff
+// source-over operator with the coverage of each pixel as additional source
+// alpha. If Blend is not BlendNormal, the source colour is first combined
+// with the backdrop using the blend function, following the compositing
+// formula of the PDF specification.
+//
+// Device pixel (x, y) of the rasterizer corresponds to pixel (x, y) of the
+// destination image. Coverage outside Dst.Bounds() is ignored.
+//
+// A Compositor is not safe for concurrent use.
+type Compositor struct {
+	// Dst is the image being painted. Fast paths are used for *image.RGBA,
+	// *image.NRGBA, *image.Alpha and *image.Gray; other image types are
+	// accessed through the draw.Image interface.
+	Dst draw.Image
+
+	// Paint supplies the source colour of each pixel.
+	Paint Paint
+
+	// Blend is the blend mode.
+	Blend BlendMode
+
+	// Alpha is a constant opacity in the range [0, 1], which multiplies the
+	// alpha of the paint (PDF parameters CA and ca). New sets Alpha to 1.
+	Alpha float32
+
+	// Mask, if not nil, is a soft mask which multiplies the coverage of
+	// every pixel.
+	Mask *SoftMask
+
+	// layers is the stack of active transparency groups
+	layers []*layer
+
+	// src holds the source colours of the current row (reused across calls)
+	src []RGBA
+
+	// cov holds coverage scaled by Alpha (reused across calls)
+	cov []float32
+
+	// backdrop and initial hold destination pixels for the blend modes and
+	// knockout groups (reused across calls)
+	backdrop []RGBA
+	initial  []RGBA
+}
+
+// Paint supplies source colours for the pixels of an image.
+type Paint interface {
+	// Shade writes the source colours of pixels (xMin+i, y) to dst[i],
+	// for i = 0, …, len(dst)-1. Pixel coordinates are in device space;
+	// the colour of a pixel is evaluated at its centre.
+	Shade(y, xMin int, dst []RGBA)
+}
+
+// RGBA is a premultiplied colour with components in the range [0, 1].
+type RGBA struct {
+	R, G, B, A float32
+}
+
+// NewRGBA converts a colour to premultiplied floating point components.
+func NewRGBA(col color.Color) RGBA {
+	r, g, b, a := col.RGBA()
+	return RGBA{
+		R: float32(r) / 0xffff,
+		G: float32(g) / 0xffff,
+		B: float32(b) / 0xffff,
+		A: float32(a) / 0xffff,
+	}
+}
+
+// Solid is a Paint which uses the same colour for every pixel.
+type Solid RGBA
+
+// NewSolid returns a Paint for the given colour.
+func NewSolid(col color.Color) Solid {
+	return Solid(NewRGBA(col))
+}
+
+// Shade implements the Paint interface.
+func (s Solid) Shade(y, xMin int, dst []RGBA) {
+	for i := range dst {
+		dst[i] = RGBA(s)
+	}
+}
+
+// New returns a Compositor which paints the colour c into dst.
+func New(dst draw.Image, c color.Color) *Compositor {
+	return &Compositor{Dst: dst, Paint: NewSolid(c), Alpha: 1}
+}
+
+// SetColor sets Paint to the solid colour col.
+func (c *Compositor) SetColor(col color.Color) {
+	c.Paint = NewSolid(col)
+}
+
+// Emit blends one row of coverage values into the destination image.
+// The signature matches the emit callback of raster.Rasterizer.
+func (c *Compositor) Emit(y, xMin int, coverage []float32) {
+	b := c.Dst.Bounds()
+	if y < b.Min.Y || y >= b.Max.Y {
+		return
+	}
+	if xMin < b.Min.X {
+		skip := b.Min.X - xMin
+		if skip >= len(coverage) {
+			return
+		}
+		coverage = coverage[skip:]
+		xMin = b.Min.X
+	}
+	if xMin+len(coverage) > b.Max.X {
+		if xMin >= b.Max.X {
+			return
+		}
+		coverage = coverage[:b.Max.X-xMin]
+	}
+
+	n := len(coverage)
+	shape := coverage
+	if c.Alpha < 1 || c.Mask != nil {
+		if cap(c.cov) < n {
+			c.cov = make([]float32, n)
+		}
+		cov := c.cov[:n]
+		for i, v := range coverage {
+			cov[i] = v * c.Alpha
+		}
+		if c.Mask != nil {
+			c.Mask.Apply(y, xMin, cov)
+		}
+		coverage = cov
+	}
+
+	if cap(c.src) < n {
+		c.src = make([]RGBA, n)
+	}
+	src := c.src[:n]
+	c.Paint.Shade(y, xMin, src)
+
+	l := c.top()
+	knockout := l != nil && l.group.Knockout
+	if l != nil && l.alpha != nil {
+		ag := l.alphaRow(y, xMin, n)
+		for i, cov := range coverage {
+			a := cov * src[i].A
+			if knockout {
+				ag[i] = ag[i]*(1-shape[i]) + a
+			} else {
+				ag[i] += a - ag[i]*a
+			}
+		}
+	}
+
+	if c.Blend != BlendNormal || knockout {
+		if cap(c.backdrop) < n {
+			c.backdrop = make([]RGBA, n)
+		}
+		var initial []RGBA
+		if knockout {
+			if cap(c.initial) < n {
+				c.initial = make([]RGBA, n)
+			}
+			initial = c.initial[:n]
+			loadRow(l.initial, y, xMin, initial)
+		}
+		blendRow(c.Dst, c.Blend, y, xMin, src, shape, coverage, c.backdrop[:n], initial)
+		return
+	}
+
+	switch dst := c.Dst.(type) {
+	case *image.RGBA:
+		emitRGBA(dst, y, xMin, src, coverage)
+	case *image.NRGBA:
+		emitNRGBA(dst, y, xMin, src, coverage)
+	case *image.Alpha:
+		emitAlpha(dst, y, xMin, src, coverage)
+	case *image.Gray:
+		emitGray(dst, y, xMin, src, coverage)
+	default:
+		emitGeneric(c.Dst, y, xMin, src, coverage)
+	}
+}
+
+// emitRGBA is the fast path for premultiplied 8-bit RGBA images.
+func emitRGBA(dst *image.RGBA, y, xMin int, src []RGBA, coverage []float32) {
+	row := dst.Pix[dst.PixOffset(xMin, y):]
+	for i, cov := range coverage {
+		if cov <= 0 {
+			continue
+		}
+		s := src[i]
+		p := row[4*i : 4*i+4 : 4*i+4]
+		if cov >= 1 && s.A >= 1 {
+			p[0] = to8(s.R * 255)
+			p[1] = to8(s.G * 255)
+			p[2] = to8(s.B * 255)
+			p[3] = 255
+			continue
+		}
+		k := 1 - s.A*cov
+		cov *= 255
+		p[0] = to8(s.R*cov + float32(p[0])*k)
+		p[1] = to8(s.G*cov + float32(p[1])*k)
+		p[2] = to8(s.B*cov + float32(p[2])*k)
+		p[3] = to8(s.A*cov + float32(p[3])*k)
+	}
+}
+
+// emitNRGBA is the fast path for non-premultiplied 8-bit RGBA images.
+func emitNRGBA(dst *image.NRGBA, y, xMin int, src []RGBA, coverage []float32) {
+	row := dst.Pix[dst.PixOffset(xMin, y):]
+	for i, cov := range coverage {
+		if cov <= 0 {
+			continue
+		}
+		s := src[i]
+		p := row[4*i : 4*i+4 : 4*i+4]
+		da := float32(p[3]) / 255
+		k := (1 - s.A*cov) * da
+		a := s.A*cov + k
+		if a <= 0 {
+			continue
+		}
+		scale := 255 / a
+		p[0] = to8((s.R*cov + float32(p[0])/255*k) * scale)
+		p[1] = to8((s.G*cov + float32(p[1])/255*k) * scale)
+		p[2] = to8((s.B*cov + float32(p[2])/255*k) * scale)
+		p[3] = to8(a * 255)
+	}
+}
+
+// emitAlpha is the fast path for alpha-only images.
+func emitAlpha(dst *image.Alpha, y, xMin int, src []RGBA, coverage []float32) {
+	row := dst.Pix[dst.PixOffset(xMin, y):]
+	for i, cov := range coverage {
+		if cov <= 0 {
+			continue
+		}
+		a := src[i].A * cov
+		row[i] = to8(a*255 + float32(row[i])*(1-a))
+	}
+}
+
+// emitGray is the fast path for opaque grayscale images.
+func emitGray(dst *image.Gray, y, xMin int, src []RGBA, coverage []float32) {
+	row := dst.Pix[dst.PixOffset(xMin, y):]
+	for i, cov := range coverage {
+		if cov <= 0 {
+			continue
+		}
+		s := src[i]
+		// same weights as color.GrayModel
+		lum := (0.299*s.R + 0.587*s.G + 0.114*s.B) * 255
+		row[i] = to8(lum*cov + float32(row[i])*(1-s.A*cov))
+	}
+}
+
+// emitGeneric blends into an arbitrary draw.Image, one pixel at a time.
+func emitGeneric(dst draw.Image, y, xMin int, src []RGBA, coverage []float32) {
+	for i, cov := range coverage {
+		if cov <= 0 {
+			continue
+		}
+		s := src[i]
+		x := xMin + i
+		dr, dg, db, da := dst.At(x, y).RGBA()
+		k := (1 - s.A*cov) / 0xffff
+		dst.Set(x, y, color.RGBA64{
+			R: to16(s.R*cov + float32(dr)*k),
+			G: to16(s.G*cov + float32(dg)*k),
+			B: to16(s.B*cov + float32(db)*k),
+			A: to16(s.A*cov + float32(da)*k),
+		})
+	}
+}
+
+// to8 rounds a value in [0, 255] to the nearest byte, clamping out-of-range
+// values.
+func to8(v float32) uint8 {
+	if v <= 0 {
+		return 0
+	}
+	if v >= 255 {
+		return 255
+	}
+	return uint8(v + 0.5)
+}
+
+// to16 converts a value in [0, 1] to a 16-bit colour component, clamping
+// out-of-range values.
+func to16(v float32) uint16 {
+	if v <= 0 {
+		return 0
+	}
+	if v >= 1 {
+		return 0xffff
+	}
+	return uint16(v*0xffff + 0.5)
+}
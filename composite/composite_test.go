@@ -0,0 +1,148 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package composite
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"seehuhn.de/go/geom/path"
+	"seehuhn.de/go/geom/rect"
+	"seehuhn.de/go/geom/vec"
+	"seehuhn.de/go/raster"
+)
+
+// opaqueImage hides the concrete type of an image, forcing the generic
+// code path.
+type opaqueImage struct {
+	draw.Image
+}
+
+// circle returns a polygonal approximation of a circle.
+func circle(cx, cy, radius float64) *path.Data {
+	const k = 0.5522847498
+	kr := k * radius
+	pt := func(x, y float64) vec.Vec2 { return vec.Vec2{X: x, Y: y} }
+	return (&path.Data{}).
+		MoveTo(pt(cx+radius, cy)).
+		CubeTo(pt(cx+radius, cy+kr), pt(cx+kr, cy+radius), pt(cx, cy+radius)).
+		CubeTo(pt(cx-kr, cy+radius), pt(cx-radius, cy+kr), pt(cx-radius, cy)).
+		CubeTo(pt(cx-radius, cy-kr), pt(cx-kr, cy-radius), pt(cx, cy-radius)).
+		CubeTo(pt(cx+kr, cy-radius), pt(cx+radius, cy-kr), pt(cx+radius, cy)).
+		Close()
+}
+
+// paint fills a circle into dst, first with an opaque colour and then with
+// a translucent one, so that every blending branch is exercised.
+func paint(dst draw.Image) {
+	b := dst.Bounds()
+	r := raster.NewRasterizer(rect.Rect{
+		LLx: float64(b.Min.X), LLy: float64(b.Min.Y),
+		URx: float64(b.Max.X), URy: float64(b.Max.Y),
+	})
+	c := New(dst, color.RGBA{R: 200, G: 40, B: 10, A: 255})
+	r.FillNonZero(circle(10, 10, 7).Iter(), c.Emit)
+	c.SetColor(color.NRGBA{R: 20, G: 90, B: 240, A: 128})
+	r.FillNonZero(circle(16, 14, 6).Iter(), c.Emit)
+}
+
+func TestFastPaths(t *testing.T) {
+	bounds := image.Rect(-2, 1, 22, 23)
+	background := color.NRGBA{R: 250, G: 240, B: 200, A: 200}
+
+	images := []struct {
+		name string
+		new  func() draw.Image
+	}{
+		{"RGBA", func() draw.Image { return image.NewRGBA(bounds) }},
+		{"NRGBA", func() draw.Image { return image.NewNRGBA(bounds) }},
+		{"Alpha", func() draw.Image { return image.NewAlpha(bounds) }},
+		{"Gray", func() draw.Image { return image.NewGray(bounds) }},
+	}
+	for _, tc := range images {
+		t.Run(tc.name, func(t *testing.T) {
+			fast := tc.new()
+			generic := tc.new()
+			draw.Draw(fast, bounds, image.NewUniform(background), image.Point{}, draw.Src)
+			draw.Draw(generic, bounds, image.NewUniform(background), image.Point{}, draw.Src)
+
+			paint(fast)
+			paint(opaqueImage{generic})
+
+			for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+				for x := bounds.Min.X; x < bounds.Max.X; x++ {
+					r1, g1, b1, a1 := fast.At(x, y).RGBA()
+					r2, g2, b2, a2 := generic.At(x, y).RGBA()
+					if !close16(r1, r2) || !close16(g1, g2) || !close16(b1, b2) || !close16(a1, a2) {
+						t.Fatalf("pixel (%d,%d): fast %v, generic %v",
+							x, y, fast.At(x, y), generic.At(x, y))
+					}
+				}
+			}
+		})
+	}
+}
+
+// close16 reports whether two 16-bit colour components agree up to the
+// rounding error of an 8-bit image.
+func close16(a, b uint32) bool {
+	d := int(a>>8) - int(b>>8)
+	return d >= -1 && d <= 1
+}
+
+func TestSourceOver(t *testing.T) {
+	dst := image.NewRGBA(image.Rect(0, 0, 4, 1))
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+
+	c := New(dst, color.RGBA{R: 255, A: 255})
+	c.Emit(0, 1, []float32{1, 0.5, 0})
+
+	want := []color.RGBA{
+		{R: 255, G: 255, B: 255, A: 255},
+		{R: 255, G: 0, B: 0, A: 255},
+		{R: 255, G: 128, B: 128, A: 255},
+		{R: 255, G: 255, B: 255, A: 255},
+	}
+	for x, w := range want {
+		if got := dst.RGBAAt(x, 0); got != w {
+			t.Errorf("pixel %d: got %v, want %v", x, got, w)
+		}
+	}
+}
+
+func TestEmitOutsideBounds(t *testing.T) {
+	dst := image.NewAlpha(image.Rect(0, 0, 3, 3))
+	c := New(dst, color.Opaque)
+
+	c.Emit(-1, 0, []float32{1, 1, 1})
+	c.Emit(3, 0, []float32{1, 1, 1})
+	c.Emit(1, -5, []float32{1, 1, 1})
+	c.Emit(1, 3, []float32{1, 1, 1})
+	c.Emit(1, -1, []float32{1, 1, 1, 1, 1})
+
+	for i, v := range dst.Pix {
+		var want uint8
+		if i >= 3 && i < 6 {
+			want = 255
+		}
+		if v != want {
+			t.Errorf("Pix[%d] = %d, want %d", i, v, want)
+		}
+	}
+}
@@ -0,0 +1,357 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package composite
+
+import (
+	"image/color"
+	"math"
+	"slices"
+	"sort"
+
+	"seehuhn.de/go/geom/matrix"
+	"seehuhn.de/go/geom/vec"
+)
+
+// Stop is a colour stop of a gradient.
+type Stop struct {
+	// Offset is the position of the stop along the gradient, in the range
+	// [0, 1].
+	Offset float64
+
+	// Color is the colour of the gradient at Offset.
+	Color color.Color
+}
+
+// SampleStops approximates a colour function on [0, 1] by n+1 equally spaced
+// stops. This can be used to convert PDF shading functions (sampled,
+// exponential or stitching functions), where the parameter t has been
+// mapped from the shading's Domain to [0, 1].
+func SampleStops(f func(t float64) color.Color, n int) []Stop {
+	n = max(n, 1)
+	stops := make([]Stop, n+1)
+	for i := range stops {
+		t := float64(i) / float64(n)
+		stops[i] = Stop{Offset: t, Color: f(t)}
+	}
+	return stops
+}
+
+// ramp maps gradient positions to colours by linear interpolation between
+// stops. Interpolation is done on non-premultiplied components.
+type ramp struct {
+	offsets []float64
+	colors  []RGBA // non-premultiplied
+}
+
+func newRamp(stops []Stop) ramp {
+	sorted := slices.Clone(stops)
+	slices.SortStableFunc(sorted, func(a, b Stop) int {
+		switch {
+		case a.Offset < b.Offset:
+			return -1
+		case a.Offset > b.Offset:
+			return 1
+		}
+		return 0
+	})
+
+	r := ramp{
+		offsets: make([]float64, len(sorted)),
+		colors:  make([]RGBA, len(sorted)),
+	}
+	for i, s := range sorted {
+		c := color.NRGBA64Model.Convert(s.Color).(color.NRGBA64)
+		r.offsets[i] = s.Offset
+		r.colors[i] = RGBA{
+			R: float32(c.R) / 0xffff,
+			G: float32(c.G) / 0xffff,
+			B: float32(c.B) / 0xffff,
+			A: float32(c.A) / 0xffff,
+		}
+	}
+	return r
+}
+
+// at returns the premultiplied colour at position t. Positions outside
+// the range of the stops use the colour of the nearest stop.
+func (r *ramp) at(t float64) RGBA {
+	n := len(r.offsets)
+	if n == 0 {
+		return RGBA{}
+	}
+
+	var c RGBA
+	switch {
+	case t <= r.offsets[0]:
+		c = r.colors[0]
+	case t >= r.offsets[n-1]:
+		c = r.colors[n-1]
+	default:
+		i := sort.SearchFloat64s(r.offsets, t) // offsets[i-1] < t <= offsets[i]
+		t0, t1 := r.offsets[i-1], r.offsets[i]
+		u := float32((t - t0) / (t1 - t0))
+		a, b := r.colors[i-1], r.colors[i]
+		c = RGBA{
+			R: a.R + (b.R-a.R)*u,
+			G: a.G + (b.G-a.G)*u,
+			B: a.B + (b.B-a.B)*u,
+			A: a.A + (b.A-a.A)*u,
+		}
+	}
+	return RGBA{R: c.R * c.A, G: c.G * c.A, B: c.B * c.A, A: c.A}
+}
+
+// gradient holds the state shared by all gradient paints.
+type gradient struct {
+	ramp   ramp
+	extend [2]bool
+
+	// inv maps device space to gradient space
+	inv matrix.Matrix
+
+	// valid is false if the gradient matrix is singular; nothing is
+	// painted in this case.
+	valid bool
+}
+
+func newGradient(m matrix.Matrix, stops []Stop, extend [2]bool) gradient {
+	g := gradient{
+		ramp:   newRamp(stops),
+		extend: extend,
+	}
+	if m[0]*m[3]-m[1]*m[2] != 0 {
+		g.inv = m.Inv()
+		g.valid = true
+	}
+	return g
+}
+
+// start returns the gradient-space position of the centre of pixel
+// (xMin, y), and the step between horizontally adjacent pixels.
+func (g *gradient) start(y, xMin int) (p, step vec.Vec2) {
+	px, py := g.inv.Apply(float64(xMin)+0.5, float64(y)+0.5)
+	return vec.Vec2{X: px, Y: py}, vec.Vec2{X: g.inv[0], Y: g.inv[1]}
+}
+
+// color returns the colour at gradient parameter t, taking the extend
+// flags into account.
+func (g *gradient) color(t float64) RGBA {
+	if t < 0 {
+		if !g.extend[0] {
+			return RGBA{}
+		}
+		t = 0
+	} else if t > 1 {
+		if !g.extend[1] {
+			return RGBA{}
+		}
+		t = 1
+	}
+	return g.ramp.at(t)
+}
+
+// LinearGradient is an axial gradient (PDF shading type 2). The colour
+// varies along the axis between two points and is constant on lines
+// perpendicular to it.
+type LinearGradient struct {
+	gradient
+
+	p0 vec.Vec2
+	d  vec.Vec2 // (p1-p0) / |p1-p0|², so that t = (p-p0)·d
+}
+
+// NewLinearGradient returns a gradient which varies from stop offset 0 at
+// p0 to stop offset 1 at p1.
+//
+// The points are given in gradient space, and m maps gradient space to
+// device space. For a PDF shading, m is the CTM at the time the shading is
+// painted (for shading patterns: the pattern matrix). The extend flags
+// correspond to the PDF Extend array: if extend[0] is set, the gradient is
+// extended beyond p0 using the colour at offset 0, and similarly for
+// extend[1] and p1. Pixels outside the gradient are left unpainted.
+func NewLinearGradient(m matrix.Matrix, p0, p1 vec.Vec2, stops []Stop, extend [2]bool) *LinearGradient {
+	g := &LinearGradient{
+		gradient: newGradient(m, stops, extend),
+		p0:       p0,
+	}
+	d := p1.Sub(p0)
+	if l2 := d.Dot(d); l2 > 0 {
+		g.d = d.Mul(1 / l2)
+	} else {
+		g.valid = false
+	}
+	return g
+}
+
+// Shade implements the Paint interface.
+func (g *LinearGradient) Shade(y, xMin int, dst []RGBA) {
+	if !g.valid {
+		clear(dst)
+		return
+	}
+	p, step := g.start(y, xMin)
+	t := p.Sub(g.p0).Dot(g.d)
+	dt := step.Dot(g.d)
+	for i := range dst {
+		dst[i] = g.color(t + float64(i)*dt)
+	}
+}
+
+// RadialGradient is a two-circle radial gradient (PDF shading type 3).
+// The gradient is formed by the family of circles obtained by linearly
+// interpolating centre and radius between a start and an end circle.
+type RadialGradient struct {
+	gradient
+
+	c0     vec.Vec2
+	r0     float64
+	dc     vec.Vec2 // c1 - c0
+	dr     float64  // r1 - r0
+	a      float64  // dc·dc - dr²
+	linear bool     // a is (nearly) zero
+}
+
+// NewRadialGradient returns a gradient which varies from stop offset 0 on
+// the circle with centre c0 and radius r0, to stop offset 1 on the circle
+// with centre c1 and radius r1.
+//
+// As for NewLinearGradient, the circles are given in gradient space, m maps
+// gradient space to device space, and extend corresponds to the PDF Extend
+// array. Where several circles pass through a pixel, the one with the
+// largest parameter is used, as required by the PDF specification.
+func NewRadialGradient(m matrix.Matrix, c0 vec.Vec2, r0 float64, c1 vec.Vec2, r1 float64, stops []Stop, extend [2]bool) *RadialGradient {
+	g := &RadialGradient{
+		gradient: newGradient(m, stops, extend),
+		c0:       c0,
+		r0:       r0,
+		dc:       c1.Sub(c0),
+		dr:       r1 - r0,
+	}
+	g.a = g.dc.Dot(g.dc) - g.dr*g.dr
+	scale := g.dc.Dot(g.dc) + g.dr*g.dr
+	if scale == 0 {
+		g.valid = false // both circles coincide
+	}
+	g.linear = math.Abs(g.a) <= 1e-12*scale
+	return g
+}
+
+// Shade implements the Paint interface.
+func (g *RadialGradient) Shade(y, xMin int, dst []RGBA) {
+	if !g.valid {
+		clear(dst)
+		return
+	}
+	p, step := g.start(y, xMin)
+	for i := range dst {
+		q := p.Add(step.Mul(float64(i)))
+		t, ok := g.param(q)
+		if !ok {
+			dst[i] = RGBA{}
+			continue
+		}
+		dst[i] = g.ramp.at(min(max(t, 0), 1))
+	}
+}
+
+// param finds the largest s such that q lies on the circle with centre
+// c0 + s*dc and radius r0 + s*dr, subject to the radius being non-negative
+// and s being in [0, 1] or in an extended range.
+func (g *RadialGradient) param(q vec.Vec2) (float64, bool) {
+	pd := q.Sub(g.c0)
+	b := pd.Dot(g.dc) + g.r0*g.dr
+	c := pd.Dot(pd) - g.r0*g.r0
+
+	// |pd - s*dc|² = (r0 + s*dr)²  ⇔  a s² - 2b s + c = 0
+	if g.linear {
+		if b == 0 {
+			return 0, false
+		}
+		s := c / (2 * b)
+		return s, g.accept(s)
+	}
+
+	disc := b*b - g.a*c
+	if disc < 0 {
+		return 0, false
+	}
+	sq := math.Sqrt(disc)
+	s1 := (b + sq) / g.a
+	s2 := (b - sq) / g.a
+	if s1 < s2 {
+		s1, s2 = s2, s1
+	}
+	if g.accept(s1) {
+		return s1, true
+	}
+	if g.accept(s2) {
+		return s2, true
+	}
+	return 0, false
+}
+
+// accept reports whether the circle with parameter s is part of the
+// gradient.
+func (g *RadialGradient) accept(s float64) bool {
+	if g.r0+s*g.dr < 0 {
+		return false
+	}
+	switch {
+	case s < 0:
+		return g.extend[0]
+	case s > 1:
+		return g.extend[1]
+	}
+	return true
+}
+
+// SweepGradient is a conic gradient, where the colour varies with the angle
+// around a centre point. Stop offset 0 and 1 both correspond to the start
+// angle; offsets increase in the direction from the gradient-space x-axis
+// towards the y-axis.
+type SweepGradient struct {
+	gradient
+
+	center vec.Vec2
+	angle  float64
+}
+
+// NewSweepGradient returns a sweep gradient around center, starting at the
+// given angle (in radians). The centre is given in gradient space, and m
+// maps gradient space to device space.
+func NewSweepGradient(m matrix.Matrix, center vec.Vec2, angle float64, stops []Stop) *SweepGradient {
+	return &SweepGradient{
+		gradient: newGradient(m, stops, [2]bool{true, true}),
+		center:   center,
+		angle:    angle,
+	}
+}
+
+// Shade implements the Paint interface.
+func (g *SweepGradient) Shade(y, xMin int, dst []RGBA) {
+	if !g.valid {
+		clear(dst)
+		return
+	}
+	p, step := g.start(y, xMin)
+	for i := range dst {
+		d := p.Add(step.Mul(float64(i))).Sub(g.center)
+		t := (math.Atan2(d.Y, d.X) - g.angle) / (2 * math.Pi)
+		t -= math.Floor(t)
+		dst[i] = g.ramp.at(t)
+	}
+}
@@ -0,0 +1,180 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package composite
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"seehuhn.de/go/geom/matrix"
+	"seehuhn.de/go/geom/vec"
+)
+
+var blackToWhite = []Stop{
+	{Offset: 0, Color: color.Black},
+	{Offset: 1, Color: color.White},
+}
+
+func closeTo(a, b float32) bool {
+	return math.Abs(float64(a-b)) < 1e-5
+}
+
+func TestLinearGradient(t *testing.T) {
+	// gradient from x=2 to x=6 in device space
+	g := NewLinearGradient(matrix.Identity,
+		vec.Vec2{X: 2, Y: 0}, vec.Vec2{X: 6, Y: 0},
+		blackToWhite, [2]bool{false, true})
+
+	dst := make([]RGBA, 8)
+	g.Shade(3, 0, dst)
+
+	for i, c := range dst {
+		x := float32(i) + 0.5
+		var want RGBA
+		switch {
+		case x < 2: // not extended
+		case x > 6:
+			want = RGBA{1, 1, 1, 1}
+		default:
+			v := (x - 2) / 4
+			want = RGBA{v, v, v, 1}
+		}
+		if !closeTo(c.R, want.R) || !closeTo(c.A, want.A) {
+			t.Errorf("pixel %d: got %v, want %v", i, c, want)
+		}
+	}
+}
+
+func TestLinearGradientCTM(t *testing.T) {
+	// The gradient runs from 0 to 1 in gradient space, the matrix scales
+	// this to 0 to 10 in device space, rotated by 90 degrees.
+	m := matrix.Scale(10, 10).Mul(matrix.Rotate(math.Pi / 2))
+	g := NewLinearGradient(m,
+		vec.Vec2{X: 0, Y: 0}, vec.Vec2{X: 1, Y: 0},
+		blackToWhite, [2]bool{})
+
+	dst := make([]RGBA, 3)
+	for y := range 10 {
+		g.Shade(y, -1, dst)
+		want := (float32(y) + 0.5) / 10
+		for i, c := range dst {
+			if !closeTo(c.G, want) {
+				t.Errorf("pixel (%d,%d): got %g, want %g", i-1, y, c.G, want)
+			}
+		}
+	}
+}
+
+func TestGradientStops(t *testing.T) {
+	stops := []Stop{
+		{Offset: 1, Color: color.RGBA{B: 255, A: 255}},
+		{Offset: 0, Color: color.RGBA{R: 255, A: 255}},
+		{Offset: 0.5, Color: color.RGBA{G: 255, A: 255}},
+	}
+	r := newRamp(stops)
+
+	cases := []struct {
+		t    float64
+		want RGBA
+	}{
+		{-1, RGBA{1, 0, 0, 1}},
+		{0, RGBA{1, 0, 0, 1}},
+		{0.25, RGBA{0.5, 0.5, 0, 1}},
+		{0.5, RGBA{0, 1, 0, 1}},
+		{0.75, RGBA{0, 0.5, 0.5, 1}},
+		{2, RGBA{0, 0, 1, 1}},
+	}
+	for _, tc := range cases {
+		got := r.at(tc.t)
+		if !closeTo(got.R, tc.want.R) || !closeTo(got.G, tc.want.G) ||
+			!closeTo(got.B, tc.want.B) || !closeTo(got.A, tc.want.A) {
+			t.Errorf("at(%g) = %v, want %v", tc.t, got, tc.want)
+		}
+	}
+}
+
+func TestRadialGradient(t *testing.T) {
+	// concentric circles: t is the distance from the centre, between
+	// radius 2 and 6
+	c := vec.Vec2{X: 0.5, Y: 0.5}
+	g := NewRadialGradient(matrix.Identity, c, 2, c, 6, blackToWhite, [2]bool{false, false})
+
+	dst := make([]RGBA, 8)
+	g.Shade(0, 0, dst)
+	for i, col := range dst {
+		d := float32(i)
+		var want RGBA
+		if d >= 2 && d <= 6 {
+			v := (d - 2) / 4
+			want = RGBA{v, v, v, 1}
+		}
+		if !closeTo(col.R, want.R) || !closeTo(col.A, want.A) {
+			t.Errorf("pixel %d: got %v, want %v", i, col, want)
+		}
+	}
+}
+
+func TestRadialGradientExtend(t *testing.T) {
+	// A cone: the start circle is a point at the origin, the end circle
+	// has radius 1 around (2, 0). Extending beyond the end fills the
+	// cone, but not the area behind the apex.
+	g := NewRadialGradient(matrix.Identity,
+		vec.Vec2{}, 0, vec.Vec2{X: 2, Y: 0}, 1,
+		blackToWhite, [2]bool{true, true})
+
+	// Both s=4/3 and s=4 give circles through (4, 0); the larger one
+	// wins.
+	a, ok := g.param(vec.Vec2{X: 4, Y: 0})
+	if !ok || math.Abs(a-4) > 1e-9 {
+		t.Errorf("param on axis: got %g, %t", a, ok)
+	}
+	if _, ok := g.param(vec.Vec2{X: -1, Y: 0}); ok {
+		t.Errorf("point behind the apex is painted")
+	}
+}
+
+func TestSweepGradient(t *testing.T) {
+	g := NewSweepGradient(matrix.Identity, vec.Vec2{}, 0, blackToWhite)
+
+	// pixel centres at (0.5, 0.5) and (-0.5, 0.5): angles 45° and 135°
+	dst := make([]RGBA, 2)
+	g.Shade(0, -1, dst)
+	if want := float32(3.0 / 8); !closeTo(dst[0].R, want) {
+		t.Errorf("135°: got %g, want %g", dst[0].R, want)
+	}
+	if want := float32(1.0 / 8); !closeTo(dst[1].R, want) {
+		t.Errorf("45°: got %g, want %g", dst[1].R, want)
+	}
+}
+
+func TestCompositorPaint(t *testing.T) {
+	dst := image.NewGray(image.Rect(0, 0, 4, 1))
+	c := New(dst, color.White)
+	c.Paint = NewLinearGradient(matrix.Identity,
+		vec.Vec2{X: 0, Y: 0}, vec.Vec2{X: 4, Y: 0},
+		blackToWhite, [2]bool{})
+	c.Emit(0, 0, []float32{1, 1, 1, 1})
+
+	want := []uint8{32, 96, 159, 223}
+	for i, v := range dst.Pix {
+		if d := int(v) - int(want[i]); d < -1 || d > 1 {
+			t.Errorf("pixel %d: got %d, want %d", i, v, want[i])
+		}
+	}
+}
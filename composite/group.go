@@ -0,0 +1,203 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package composite
+
+import (
+	"image"
+	"image/draw"
+)
+
+// Group describes a PDF transparency group.
+type Group struct {
+	// Isolated groups start from a transparent backdrop. Non-isolated groups
+	// start from the current contents of the destination, and the backdrop
+	// is removed again before the group is composited.
+	Isolated bool
+
+	// In a knockout group, each object is composited with the initial
+	// backdrop of the group, rather than with the objects painted before
+	// it inside the group.
+	Knockout bool
+}
+
+// layer is an entry of the transparency group stack.
+type layer struct {
+	group  Group
+	parent draw.Image
+	img    *image.RGBA
+
+	// initial is the initial backdrop of a knockout group, nil otherwise
+	initial *image.RGBA
+
+	// alpha holds the group alpha of a non-isolated group, excluding the
+	// backdrop; nil for isolated groups
+	alpha []float32
+
+	// compositor state at the time the group was pushed
+	paint Paint
+	blend BlendMode
+	opa   float32
+	mask  *SoftMask
+}
+
+// PushGroup starts a transparency group. Subsequent painting goes to an
+// off-screen buffer of the same size as the destination image, until the
+// matching call to PopGroup.
+//
+// Paint, Blend, Alpha and Mask are saved and reset to their defaults, so
+// that the objects inside the group can set their own values. The saved
+// values are used when the finished group is composited by PopGroup.
+func (c *Compositor) PushGroup(g Group) {
+	b := c.Dst.Bounds()
+	l := &layer{
+		group:  g,
+		parent: c.Dst,
+		img:    image.NewRGBA(b),
+		paint:  c.Paint,
+		blend:  c.Blend,
+		opa:    c.Alpha,
+		mask:   c.Mask,
+	}
+	if !g.Isolated {
+		draw.Draw(l.img, b, c.Dst, b.Min, draw.Src)
+		l.alpha = make([]float32, b.Dx()*b.Dy())
+	}
+	if g.Knockout {
+		l.initial = image.NewRGBA(b)
+		copy(l.initial.Pix, l.img.Pix)
+	}
+	c.layers = append(c.layers, l)
+
+	c.Dst = l.img
+	c.Blend = BlendNormal
+	c.Alpha = 1
+	c.Mask = nil
+}
+
+// PopGroup ends the innermost transparency group and composites it into
+// the enclosing destination, using the Blend, Alpha and Mask values which
+// were in effect when the group was pushed. PopGroup does nothing if no
+// group is active.
+func (c *Compositor) PopGroup() {
+	n := len(c.layers)
+	if n == 0 {
+		return
+	}
+	l := c.layers[n-1]
+	c.layers = c.layers[:n-1]
+
+	c.Dst = l.parent
+	c.Blend = l.blend
+	c.Alpha = l.opa
+	c.Mask = l.mask
+	c.Paint = &groupPaint{l: l}
+
+	// The shape of the group is the set of pixels where it has non-zero
+	// alpha.
+	b := l.img.Bounds()
+	shape := make([]float32, b.Dx())
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		clear(shape)
+		if l.alpha != nil {
+			for i, a := range l.alphaRow(y, b.Min.X, len(shape)) {
+				if a > 0 {
+					shape[i] = 1
+				}
+			}
+		} else {
+			row := l.img.Pix[l.img.PixOffset(b.Min.X, y):]
+			for i := range shape {
+				if row[4*i+3] > 0 {
+					shape[i] = 1
+				}
+			}
+		}
+		c.Emit(y, b.Min.X, shape)
+	}
+
+	c.Paint = l.paint
+}
+
+// GroupDepth returns the number of active transparency groups.
+func (c *Compositor) GroupDepth() int {
+	return len(c.layers)
+}
+
+// top returns the innermost active group, or nil.
+func (c *Compositor) top() *layer {
+	if len(c.layers) == 0 {
+		return nil
+	}
+	return c.layers[len(c.layers)-1]
+}
+
+// alphaRow returns the row of the group alpha buffer for pixels
+// xMin, …, xMin+n-1 of row y.
+func (l *layer) alphaRow(y, xMin, n int) []float32 {
+	b := l.img.Rect
+	start := (y-b.Min.Y)*b.Dx() + xMin - b.Min.X
+	return l.alpha[start : start+n]
+}
+
+// groupPaint supplies the contents of a finished group as source colours.
+// For non-isolated groups, the backdrop is removed using the formula from
+// section 11.4.8 of the PDF specification.
+type groupPaint struct {
+	l        *layer
+	backdrop []RGBA
+}
+
+// Shade implements the Paint interface.
+func (p *groupPaint) Shade(y, xMin int, dst []RGBA) {
+	loadRow(p.l.img, y, xMin, dst)
+	if p.l.group.Isolated {
+		return
+	}
+
+	if cap(p.backdrop) < len(dst) {
+		p.backdrop = make([]RGBA, len(dst))
+	}
+	back := p.backdrop[:len(dst)]
+	loadRow(p.l.parent, y, xMin, back)
+	ag := p.l.alphaRow(y, xMin, len(dst))
+
+	for i, cn := range dst {
+		a := ag[i]
+		if a <= 0 {
+			dst[i] = RGBA{}
+			continue
+		}
+		c0 := back[i]
+		if c0.A <= 0 || cn.A <= 0 {
+			dst[i] = cn
+			continue
+		}
+		// C = Cn + (Cn - C0)·(α0/αg - α0), with non-premultiplied colours
+		k := c0.A/a - c0.A
+		ra, rc := 1/cn.A, 1/c0.A
+		dst[i] = RGBA{
+			R: clamp01(cn.R*ra+(cn.R*ra-c0.R*rc)*k) * a,
+			G: clamp01(cn.G*ra+(cn.G*ra-c0.G*rc)*k) * a,
+			B: clamp01(cn.B*ra+(cn.B*ra-c0.B*rc)*k) * a,
+			A: a,
+		}
+	}
+}
+
+func clamp01(x float32) float32 {
+	return min(max(x, 0), 1)
+}
@@ -0,0 +1,170 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package composite
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+// span paints pixels x0, …, x1-1 of row 0 with full coverage.
+func span(c *Compositor, x0, x1 int) {
+	coverage := make([]float32, x1-x0)
+	for i := range coverage {
+		coverage[i] = 1
+	}
+	c.Emit(0, x0, coverage)
+}
+
+func newRow(w int, col color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, 1))
+	draw.Draw(img, img.Bounds(), image.NewUniform(col), image.Point{}, draw.Src)
+	return img
+}
+
+func checkPixels(t *testing.T, label string, img *image.RGBA, want []color.RGBA) {
+	t.Helper()
+	for i, w := range want {
+		if got := img.RGBAAt(i, 0); !close8(got, w) {
+			t.Errorf("%s: pixel %d: got %v, want %v", label, i, got, w)
+		}
+	}
+}
+
+func TestIsolatedGroup(t *testing.T) {
+	// Two overlapping black spans in a group with alpha 0.5: the overlap
+	// is no darker than the rest.
+	dst := newRow(4, color.White)
+	c := New(dst, color.Black)
+	c.Alpha = 0.5
+	c.PushGroup(Group{Isolated: true})
+	if c.Alpha != 1 {
+		t.Errorf("Alpha inside group: got %g", c.Alpha)
+	}
+	span(c, 0, 3)
+	span(c, 1, 4)
+	c.PopGroup()
+
+	if c.Alpha != 0.5 || c.GroupDepth() != 0 {
+		t.Errorf("state not restored")
+	}
+	g := color.RGBA{128, 128, 128, 255}
+	checkPixels(t, "isolated", dst, []color.RGBA{g, g, g, g})
+}
+
+func TestNonIsolatedGroup(t *testing.T) {
+	// With Normal blending and alpha 1, a non-isolated group gives the
+	// same result as painting directly.
+	back := color.NRGBA{R: 200, G: 100, B: 50, A: 160}
+	red := color.NRGBA{R: 255, A: 128}
+	blue := color.NRGBA{B: 255, A: 200}
+
+	direct := newRow(5, back)
+	c := New(direct, red)
+	span(c, 0, 3)
+	c.SetColor(blue)
+	span(c, 2, 4)
+
+	grouped := newRow(5, back)
+	c = New(grouped, red)
+	c.PushGroup(Group{})
+	span(c, 0, 3)
+	c.SetColor(blue)
+	span(c, 2, 4)
+	c.PopGroup()
+
+	want := make([]color.RGBA, 5)
+	for i := range want {
+		want[i] = direct.RGBAAt(i, 0)
+	}
+	checkPixels(t, "non-isolated", grouped, want)
+}
+
+func TestKnockoutGroup(t *testing.T) {
+	// In a knockout group, the second span replaces the first one.
+	dst := newRow(4, color.White)
+	c := New(dst, color.RGBA{R: 255, A: 255})
+	c.PushGroup(Group{Isolated: true, Knockout: true})
+	c.Alpha = 0.5
+	span(c, 0, 3)
+	c.SetColor(color.RGBA{B: 255, A: 255})
+	span(c, 1, 4)
+	c.PopGroup()
+
+	r := color.RGBA{255, 128, 128, 255}
+	b := color.RGBA{128, 128, 255, 255}
+	checkPixels(t, "knockout", dst, []color.RGBA{r, b, b, b})
+}
+
+func TestGroupNested(t *testing.T) {
+	dst := newRow(2, color.White)
+	c := New(dst, color.Black)
+	c.PopGroup() // no-op
+
+	c.Alpha = 0.5
+	c.PushGroup(Group{Isolated: true})
+	c.Alpha = 0.5
+	c.PushGroup(Group{Isolated: true})
+	if c.GroupDepth() != 2 {
+		t.Errorf("GroupDepth = %d, want 2", c.GroupDepth())
+	}
+	span(c, 0, 1)
+	c.PopGroup()
+	c.PopGroup()
+
+	// 0.5 · 0.5 of black over white
+	checkPixels(t, "nested", dst, []color.RGBA{{191, 191, 191, 255}, {255, 255, 255, 255}})
+}
+
+func TestSoftMask(t *testing.T) {
+	src := image.NewGray(image.Rect(0, 0, 3, 1))
+	copy(src.Pix, []uint8{0, 128, 255})
+
+	m := NewLuminosityMask(src, color.Black, nil)
+	dst := newRow(4, color.Black)
+	c := New(dst, color.White)
+	c.Mask = m
+	span(c, 0, 4)
+	checkPixels(t, "luminosity", dst, []color.RGBA{
+		{0, 0, 0, 255}, {128, 128, 128, 255}, {255, 255, 255, 255}, {0, 0, 0, 255},
+	})
+
+	// The backdrop colour shows through transparent parts of the group, and
+	// defines the mask outside the group.
+	grp := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	m = NewLuminosityMask(grp, color.White, func(l float32) float32 { return 1 - l })
+	if v := m.At(0, 0); v != 0 {
+		t.Errorf("transfer: got %g, want 0", v)
+	}
+	if v := m.At(5, 5); v != 0 {
+		t.Errorf("outside: got %g, want 0", v)
+	}
+
+	alpha := image.NewAlpha(image.Rect(1, 0, 3, 1))
+	copy(alpha.Pix, []uint8{255, 51})
+	m = NewAlphaMask(alpha)
+	coverage := []float32{1, 1, 0.5, 1}
+	m.Apply(0, 0, coverage)
+	want := []float32{0, 1, 0.1, 0}
+	for i := range want {
+		if !closeTo(coverage[i], want[i]) {
+			t.Errorf("alpha mask: pixel %d = %g, want %g", i, coverage[i], want[i])
+		}
+	}
+}
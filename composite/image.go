@@ -0,0 +1,266 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package composite
+
+import (
+	"image"
+	"math"
+
+	"seehuhn.de/go/geom/matrix"
+)
+
+// Filter selects how an image is sampled between pixel centres.
+type Filter int
+
+const (
+	// Nearest uses the colour of the nearest image pixel.
+	Nearest Filter = iota
+
+	// Bilinear interpolates linearly between the four nearest image pixels.
+	Bilinear
+
+	// Bicubic uses Catmull-Rom interpolation on the sixteen nearest image
+	// pixels.
+	Bicubic
+)
+
+// Wrap selects how an image is continued beyond its bounds.
+type Wrap int
+
+const (
+	// WrapNone leaves the area outside the image unpainted.
+	WrapNone Wrap = iota
+
+	// WrapRepeat tiles the plane with copies of the image.
+	WrapRepeat
+
+	// WrapReflect tiles the plane with copies of the image, mirroring
+	// every other copy.
+	WrapReflect
+)
+
+// ImagePaint is a Paint which samples a raster image.
+type ImagePaint struct {
+	fetch  func(x, y int) RGBA // reads a pixel, relative to the image origin
+	w, h   int
+	inv    matrix.Matrix // device space to image space
+	filter Filter
+	wrapX  Wrap
+	wrapY  Wrap
+	valid  bool
+}
+
+// NewImagePaint returns a Paint which samples img.
+//
+// The matrix m maps image space to device space. In image space, pixel
+// (i, j) of img occupies the unit square [i, i+1]×[j, j+1], where the
+// top-left pixel of img.Bounds() is pixel (0, 0). For a PDF image XObject,
+// which is mapped to the unit square of user space with the first image row
+// at the top, use
+//
+//	m = matrix.Matrix{1/w, 0, 0, -1/h, 0, 1}.Mul(ctm)
+//
+// where w and h are the image dimensions. The paint is usually used
+// to fill the image outline, i.e. the unit square transformed by the CTM.
+func NewImagePaint(img image.Image, m matrix.Matrix, filter Filter, wrapX, wrapY Wrap) *ImagePaint {
+	b := img.Bounds()
+	p := &ImagePaint{
+		fetch:  pixelReader(img),
+		w:      b.Dx(),
+		h:      b.Dy(),
+		filter: filter,
+		wrapX:  wrapX,
+		wrapY:  wrapY,
+	}
+	if p.w > 0 && p.h > 0 && m[0]*m[3]-m[1]*m[2] != 0 {
+		p.inv = m.Inv()
+		p.valid = true
+	}
+	return p
+}
+
+// NewTilingPattern returns a Paint which repeats cell across the plane
+// (PDF tiling patterns, pattern type 1). The cell must contain one
+// period of the pattern, i.e. an area of XStep×YStep in pattern space,
+// with transparent pixels where the pattern cell is not painted. This
+// image is typically obtained by rendering the pattern's content stream
+// with a Rasterizer and a Compositor.
+//
+// The matrix m maps the image space of cell to device space, as for
+// NewImagePaint.
+func NewTilingPattern(cell image.Image, m matrix.Matrix, filter Filter) *ImagePaint {
+	return NewImagePaint(cell, m, filter, WrapRepeat, WrapRepeat)
+}
+
+// pixelReader returns a function which reads pixels from img, with
+// fast paths for common image types.
+func pixelReader(img image.Image) func(x, y int) RGBA {
+	b := img.Bounds()
+	switch img := img.(type) {
+	case *image.RGBA:
+		return func(x, y int) RGBA {
+			p := img.Pix[img.PixOffset(x+b.Min.X, y+b.Min.Y):]
+			return RGBA{
+				R: float32(p[0]) / 255,
+				G: float32(p[1]) / 255,
+				B: float32(p[2]) / 255,
+				A: float32(p[3]) / 255,
+			}
+		}
+	case *image.NRGBA:
+		return func(x, y int) RGBA {
+			p := img.Pix[img.PixOffset(x+b.Min.X, y+b.Min.Y):]
+			a := float32(p[3]) / 255
+			return RGBA{
+				R: float32(p[0]) / 255 * a,
+				G: float32(p[1]) / 255 * a,
+				B: float32(p[2]) / 255 * a,
+				A: a,
+			}
+		}
+	case *image.Gray:
+		return func(x, y int) RGBA {
+			v := float32(img.Pix[img.PixOffset(x+b.Min.X, y+b.Min.Y)]) / 255
+			return RGBA{R: v, G: v, B: v, A: 1}
+		}
+	default:
+		return func(x, y int) RGBA {
+			return NewRGBA(img.At(x+b.Min.X, y+b.Min.Y))
+		}
+	}
+}
+
+// Shade implements the Paint interface.
+func (p *ImagePaint) Shade(y, xMin int, dst []RGBA) {
+	if !p.valid {
+		clear(dst)
+		return
+	}
+	u, v := p.inv.Apply(float64(xMin)+0.5, float64(y)+0.5)
+	du, dv := p.inv[0], p.inv[1]
+	for i := range dst {
+		dst[i] = p.sample(u+float64(i)*du, v+float64(i)*dv)
+	}
+}
+
+// sample returns the colour at position (u, v) in image space.
+func (p *ImagePaint) sample(u, v float64) RGBA {
+	if p.wrapX == WrapNone && (u < 0 || u >= float64(p.w)) ||
+		p.wrapY == WrapNone && (v < 0 || v >= float64(p.h)) ||
+		math.IsNaN(u) || math.IsNaN(v) {
+		return RGBA{}
+	}
+
+	switch p.filter {
+	case Bilinear:
+		u -= 0.5
+		v -= 0.5
+		fu, fv := math.Floor(u), math.Floor(v)
+		i, j := int(fu), int(fv)
+		tx, ty := float32(u-fu), float32(v-fv)
+		c00 := p.texel(i, j)
+		c10 := p.texel(i+1, j)
+		c01 := p.texel(i, j+1)
+		c11 := p.texel(i+1, j+1)
+		return lerpRGBA(lerpRGBA(c00, c10, tx), lerpRGBA(c01, c11, tx), ty)
+
+	case Bicubic:
+		u -= 0.5
+		v -= 0.5
+		fu, fv := math.Floor(u), math.Floor(v)
+		i, j := int(fu), int(fv)
+		wx := cubicWeights(float32(u - fu))
+		wy := cubicWeights(float32(v - fv))
+		var c RGBA
+		for dy := range 4 {
+			var row RGBA
+			for dx := range 4 {
+				t := p.texel(i+dx-1, j+dy-1)
+				row.R += wx[dx] * t.R
+				row.G += wx[dx] * t.G
+				row.B += wx[dx] * t.B
+				row.A += wx[dx] * t.A
+			}
+			c.R += wy[dy] * row.R
+			c.G += wy[dy] * row.G
+			c.B += wy[dy] * row.B
+			c.A += wy[dy] * row.A
+		}
+		// Catmull-Rom overshoots near sharp edges
+		c.A = min(max(c.A, 0), 1)
+		c.R = min(max(c.R, 0), c.A)
+		c.G = min(max(c.G, 0), c.A)
+		c.B = min(max(c.B, 0), c.A)
+		return c
+
+	default:
+		return p.texel(int(math.Floor(u)), int(math.Floor(v)))
+	}
+}
+
+// texel returns image pixel (i, j), applying the wrap modes to indices
+// outside the image. With WrapNone, indices are clamped to the image, so
+// that filtering near the image border does not blend in transparency.
+func (p *ImagePaint) texel(i, j int) RGBA {
+	return p.fetch(wrapIndex(i, p.w, p.wrapX), wrapIndex(j, p.h, p.wrapY))
+}
+
+// wrapIndex maps i to the range [0, n) according to the wrap mode.
+func wrapIndex(i, n int, wrap Wrap) int {
+	switch wrap {
+	case WrapRepeat:
+		i %= n
+		if i < 0 {
+			i += n
+		}
+	case WrapReflect:
+		i %= 2 * n
+		if i < 0 {
+			i += 2 * n
+		}
+		if i >= n {
+			i = 2*n - 1 - i
+		}
+	default:
+		i = min(max(i, 0), n-1)
+	}
+	return i
+}
+
+// lerpRGBA interpolates linearly between two premultiplied colours.
+func lerpRGBA(a, b RGBA, t float32) RGBA {
+	return RGBA{
+		R: a.R + (b.R-a.R)*t,
+		G: a.G + (b.G-a.G)*t,
+		B: a.B + (b.B-a.B)*t,
+		A: a.A + (b.A-a.A)*t,
+	}
+}
+
+// cubicWeights returns the Catmull-Rom weights for the four samples
+// at offsets -1, 0, 1, 2 from the sample position floor(u), where t is the
+// fractional part of u.
+func cubicWeights(t float32) [4]float32 {
+	t2 := t * t
+	t3 := t2 * t
+	return [4]float32{
+		(-t3 + 2*t2 - t) / 2,
+		(3*t3 - 5*t2 + 2) / 2,
+		(-3*t3 + 4*t2 + t) / 2,
+		(t3 - t2) / 2,
+	}
+}
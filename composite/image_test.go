@@ -0,0 +1,122 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package composite
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"seehuhn.de/go/geom/matrix"
+)
+
+// rampImage returns a w×1 grayscale image with pixel values 0, 10, 20, …
+func rampImage(w int) *image.Gray {
+	img := image.NewGray(image.Rect(5, 7, 5+w, 8))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(10 * i)
+	}
+	return img
+}
+
+func shadeRow(p Paint, y, xMin, n int) []float32 {
+	dst := make([]RGBA, n)
+	p.Shade(y, xMin, dst)
+	res := make([]float32, n)
+	for i, c := range dst {
+		res[i] = c.R * 255
+	}
+	return res
+}
+
+func checkRow(t *testing.T, label string, got, want []float32) {
+	t.Helper()
+	for i := range want {
+		if d := got[i] - want[i]; d < -1e-3 || d > 1e-3 {
+			t.Errorf("%s: pixel %d = %g, want %g", label, i, got[i], want[i])
+		}
+	}
+}
+
+func TestImagePaintNearest(t *testing.T) {
+	img := rampImage(4)
+
+	p := NewImagePaint(img, matrix.Identity, Nearest, WrapNone, WrapNone)
+	checkRow(t, "identity", shadeRow(p, 0, -1, 6), []float32{0, 0, 10, 20, 30, 0})
+
+	// each image pixel covers two device pixels
+	p = NewImagePaint(img, matrix.Scale(2, 2), Nearest, WrapNone, WrapNone)
+	checkRow(t, "scaled", shadeRow(p, 1, 0, 8), []float32{0, 0, 10, 10, 20, 20, 30, 30})
+}
+
+func TestImagePaintWrap(t *testing.T) {
+	img := rampImage(3)
+
+	p := NewImagePaint(img, matrix.Identity, Nearest, WrapRepeat, WrapRepeat)
+	checkRow(t, "repeat", shadeRow(p, 5, -3, 9), []float32{0, 10, 20, 0, 10, 20, 0, 10, 20})
+
+	p = NewImagePaint(img, matrix.Identity, Nearest, WrapReflect, WrapReflect)
+	checkRow(t, "reflect", shadeRow(p, -2, -3, 9), []float32{20, 10, 0, 0, 10, 20, 20, 10, 0})
+
+	p = NewTilingPattern(img, matrix.Translate(1, 0), Nearest)
+	checkRow(t, "tiling", shadeRow(p, 0, 0, 4), []float32{20, 0, 10, 20})
+}
+
+func TestImagePaintBilinear(t *testing.T) {
+	img := rampImage(4)
+
+	// Sample at the pixel boundaries of the image: device pixel centres
+	// fall at image positions 1, 2, 3, 4. The last one is outside the
+	// image.
+	p := NewImagePaint(img, matrix.Translate(-0.5, 0), Bilinear, WrapNone, WrapNone)
+	checkRow(t, "bilinear", shadeRow(p, 0, 0, 4), []float32{5, 15, 25, 0})
+}
+
+func TestImagePaintBicubic(t *testing.T) {
+	// Catmull-Rom reproduces linear functions exactly.
+	img := rampImage(8)
+	p := NewImagePaint(img, matrix.Translate(-0.5, 0), Bicubic, WrapNone, WrapNone)
+	checkRow(t, "bicubic", shadeRow(p, 0, 2, 4), []float32{25, 35, 45, 55})
+
+	// constant images stay constant
+	uni := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	for i := 0; i < len(uni.Pix); i += 4 {
+		copy(uni.Pix[i:], []uint8{255, 0, 0, 128})
+	}
+	p = NewImagePaint(uni, matrix.Scale(0.7, 0.7), Bicubic, WrapReflect, WrapReflect)
+	dst := make([]RGBA, 5)
+	p.Shade(1, 0, dst)
+	for i, c := range dst {
+		if !closeTo(c.A, 128.0/255) || !closeTo(c.R, c.A) || c.G != 0 {
+			t.Errorf("pixel %d: got %v", i, c)
+		}
+	}
+}
+
+func TestImagePaintGeneric(t *testing.T) {
+	img := image.NewPaletted(image.Rect(0, 0, 2, 1), color.Palette{
+		color.Black, color.RGBA{R: 255, A: 255},
+	})
+	img.Pix[1] = 1
+
+	p := NewImagePaint(img, matrix.Identity, Nearest, WrapNone, WrapNone)
+	dst := make([]RGBA, 2)
+	p.Shade(0, 0, dst)
+	if dst[0] != (RGBA{A: 1}) || dst[1] != (RGBA{R: 1, A: 1}) {
+		t.Errorf("got %v", dst)
+	}
+}
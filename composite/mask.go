@@ -0,0 +1,128 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package composite
+
+import (
+	"image"
+	"image/color"
+)
+
+// SoftMask is a per-pixel opacity, used for PDF soft masks. When a
+// Compositor has a soft mask, the coverage of every pixel is multiplied
+// by the mask value before compositing.
+//
+// A soft mask is normally obtained by rendering the mask's transparency
+// group into an *image.RGBA of the same size as the destination, using a
+// separate Compositor, and then calling NewAlphaMask or NewLuminosityMask
+// on the result.
+type SoftMask struct {
+	rect    image.Rectangle
+	values  []float32 // row-major, one value per pixel of rect
+	outside float32   // mask value outside rect
+}
+
+// NewAlphaMask returns a soft mask which uses the alpha channel of img
+// (PDF soft mask subtype Alpha). Outside the bounds of img, the mask is 0.
+func NewAlphaMask(img image.Image) *SoftMask {
+	m := newSoftMask(img.Bounds(), 0)
+	buf := make([]RGBA, m.rect.Dx())
+	for y := m.rect.Min.Y; y < m.rect.Max.Y; y++ {
+		loadRow(img, y, m.rect.Min.X, buf)
+		row := m.row(y)
+		for i, c := range buf {
+			row[i] = c.A
+		}
+	}
+	return m
+}
+
+// NewLuminosityMask returns a soft mask which uses the luminosity of img,
+// composited over the given backdrop colour (PDF soft mask subtype
+// Luminosity, with the backdrop colour from the BC entry). Outside the
+// bounds of img, the mask value is the luminosity of the backdrop colour.
+//
+// If transfer is not nil, it is applied to the luminosity values (the TR
+// entry of the soft mask dictionary).
+func NewLuminosityMask(img image.Image, backdrop color.Color, transfer func(float32) float32) *SoftMask {
+	bc := NewRGBA(backdrop)
+	bc.A = 1 // the backdrop colour is always opaque
+	value := func(c RGBA) float32 {
+		l := lum([3]float32{
+			c.R + bc.R*(1-c.A),
+			c.G + bc.G*(1-c.A),
+			c.B + bc.B*(1-c.A),
+		})
+		if transfer != nil {
+			l = transfer(l)
+		}
+		return min(max(l, 0), 1)
+	}
+
+	m := newSoftMask(img.Bounds(), value(RGBA{}))
+	buf := make([]RGBA, m.rect.Dx())
+	for y := m.rect.Min.Y; y < m.rect.Max.Y; y++ {
+		loadRow(img, y, m.rect.Min.X, buf)
+		row := m.row(y)
+		for i, c := range buf {
+			row[i] = value(c)
+		}
+	}
+	return m
+}
+
+func newSoftMask(rect image.Rectangle, outside float32) *SoftMask {
+	return &SoftMask{
+		rect:    rect,
+		values:  make([]float32, rect.Dx()*rect.Dy()),
+		outside: outside,
+	}
+}
+
+func (m *SoftMask) row(y int) []float32 {
+	w := m.rect.Dx()
+	start := (y - m.rect.Min.Y) * w
+	return m.values[start : start+w]
+}
+
+// At returns the mask value of pixel (x, y).
+func (m *SoftMask) At(x, y int) float32 {
+	if !(image.Point{x, y}).In(m.rect) {
+		return m.outside
+	}
+	return m.row(y)[x-m.rect.Min.X]
+}
+
+// Apply multiplies coverage[i] by the mask value of pixel (xMin+i, y).
+// This can be used to apply a soft mask to the output of a Rasterizer
+// outside of a Compositor.
+func (m *SoftMask) Apply(y, xMin int, coverage []float32) {
+	if y < m.rect.Min.Y || y >= m.rect.Max.Y {
+		for i := range coverage {
+			coverage[i] *= m.outside
+		}
+		return
+	}
+	row := m.row(y)
+	for i := range coverage {
+		x := xMin + i - m.rect.Min.X
+		if x < 0 || x >= len(row) {
+			coverage[i] *= m.outside
+		} else {
+			coverage[i] *= row[x]
+		}
+	}
+}
@@ -0,0 +1,409 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package raster
+
+import (
+	"math"
+	"slices"
+
+	"seehuhn.de/go/geom/path"
+	"seehuhn.de/go/geom/vec"
+)
+
+// DashPath applies Dash and DashPhase to p and returns the dashes as open
+// subpaths, in user space. Straight segments and Bézier curves are kept:
+// curves are split at the parameters where the dashes start and end,
+// measured by arc length.
+//
+// Closed subpaths are dashed including the closing segment; if the path
+// is "on" at both ends, the first and last dash are joined. Zero-length
+// dashes, and subpaths of zero length, are returned as a MoveTo followed
+// by a LineTo to the same point. If Dash is empty, or if the path would
+// have more than Limits.MaxDashes dashes, a copy of p is returned.
+func (r *Rasterizer) DashPath(p path.Path) *path.Data {
+	res := &path.Data{}
+	if len(r.Dash) == 0 ||
+		!(r.dashSegments(polygonLength(p)) <= float64(r.Limits.withDefaults().MaxDashes)) {
+		for cmd, pts := range p {
+			res.Cmds = append(res.Cmds, cmd)
+			res.Coords = append(res.Coords, pts...)
+		}
+		return res
+	}
+
+	patternLen := 0.0
+	for _, d := range r.Dash {
+		patternLen += d
+	}
+	if len(r.Dash)%2 == 1 {
+		patternLen *= 2
+	}
+	if !(patternLen > 0) {
+		return res
+	}
+	phase := math.Mod(r.DashPhase, patternLen)
+	if phase < 0 {
+		phase += patternLen
+	}
+
+	d := &dasher{dash: r.Dash, phase: phase, out: res}
+	var current, start vec.Vec2
+	inSubpath := false
+	for cmd, pts := range p {
+		switch cmd {
+		case path.CmdMoveTo:
+			if inSubpath {
+				d.finishSubpath(false)
+			}
+			current, start = pts[0], pts[0]
+			d.startSubpath(start)
+			inSubpath = true
+		case path.CmdLineTo:
+			if inSubpath {
+				d.addCurve(newDashCurve(current, pts[0]))
+				current = pts[0]
+			}
+		case path.CmdQuadTo:
+			if inSubpath {
+				d.addCurve(newDashCurve(current, pts[0], pts[1]))
+				current = pts[1]
+			}
+		case path.CmdCubeTo:
+			if inSubpath {
+				d.addCurve(newDashCurve(current, pts[0], pts[1], pts[2]))
+				current = pts[2]
+			}
+		case path.CmdClose:
+			if inSubpath {
+				if current != start {
+					d.addCurve(newDashCurve(current, start))
+				}
+				d.finishSubpath(true)
+				current = start
+				inSubpath = false
+			}
+		}
+	}
+	if inSubpath {
+		d.finishSubpath(false)
+	}
+	return res
+}
+
+// polygonLength returns the total length of the control polygons of p,
+// including closing segments. This is an upper bound for the arc length.
+func polygonLength(p path.Path) float64 {
+	var current, start vec.Vec2
+	length := 0.0
+	for cmd, pts := range p {
+		switch cmd {
+		case path.CmdMoveTo:
+			current, start = pts[0], pts[0]
+		case path.CmdClose:
+			length += start.Sub(current).Length()
+			current = start
+		default:
+			for _, pt := range pts {
+				length += pt.Sub(current).Length()
+				current = pt
+			}
+		}
+	}
+	return length
+}
+
+// dasher holds the state for DashPath.
+type dasher struct {
+	dash  []float64
+	phase float64
+	out   *path.Data
+
+	// current subpath
+	start   vec.Vec2
+	curves  []dashCurve
+	hasCmds bool // whether the subpath has drawing commands
+
+	// dashes of the current subpath
+	pieces  []dashCurve
+	offsets []int // start index of each dash in pieces
+	dashIdx int
+	remain  float64
+}
+
+func (d *dasher) startSubpath(start vec.Vec2) {
+	d.start = start
+	d.curves = d.curves[:0]
+	d.hasCmds = false
+}
+
+func (d *dasher) addCurve(c dashCurve) {
+	d.hasCmds = true
+	if c.length() > zeroLengthThreshold {
+		d.curves = append(d.curves, c)
+	}
+}
+
+// finishSubpath dashes the collected curves and writes the dashes to the
+// output path.
+func (d *dasher) finishSubpath(closed bool) {
+	if !d.hasCmds {
+		return
+	}
+	if len(d.curves) == 0 {
+		// no orientation: keep the point
+		d.out.MoveTo(d.start).LineTo(d.start)
+		return
+	}
+
+	d.pieces = d.pieces[:0]
+	d.offsets = d.offsets[:0]
+
+	// find the starting dash, as in applyDashPattern
+	n := len(d.dash)
+	d.dashIdx = 0
+	dist := d.phase
+	for dist >= d.dash[d.dashIdx%n] && d.dash[d.dashIdx%n] > 0 {
+		dist -= d.dash[d.dashIdx%n]
+		d.dashIdx++
+	}
+	d.remain = d.dash[d.dashIdx%n] - dist
+	startOn := d.dashIdx%2 == 0 && d.remain > 0
+
+	dashStart := 0
+	for _, c := range d.curves {
+		L := c.length()
+		pos := 0.0
+		for {
+			isOn := d.dashIdx%2 == 0
+			if d.remain >= L-pos {
+				if isOn {
+					d.pieces = append(d.pieces, c.sub(c.paramAt(pos), 1))
+				}
+				d.remain -= L - pos
+				break
+			}
+
+			end := pos + d.remain
+			if isOn {
+				if end-pos > zeroLengthThreshold {
+					d.pieces = append(d.pieces, c.sub(c.paramAt(pos), c.paramAt(end)))
+				} else if len(d.pieces) == dashStart {
+					pt := c.point(c.paramAt(pos))
+					d.pieces = append(d.pieces, newDashCurve(pt, pt))
+				}
+				if len(d.pieces) > dashStart {
+					d.offsets = append(d.offsets, dashStart)
+					dashStart = len(d.pieces)
+				}
+			}
+			pos = end
+			d.dashIdx++
+			d.remain = d.dash[d.dashIdx%n]
+		}
+	}
+	endOn := len(d.pieces) > dashStart
+	if endOn {
+		d.offsets = append(d.offsets, dashStart)
+	}
+	if len(d.offsets) == 0 {
+		return
+	}
+
+	// For closed subpaths which are "on" at both ends, the last dash
+	// continues into the first one.
+	first := 0
+	if closed && startOn && endOn && len(d.offsets) > 1 {
+		firstEnd := d.offsets[1]
+		d.pieces = append(d.pieces, d.pieces[:firstEnd]...)
+		first = 1
+	}
+
+	for i := first; i < len(d.offsets); i++ {
+		end := len(d.pieces)
+		if i+1 < len(d.offsets) {
+			end = d.offsets[i+1]
+		}
+		d.writeDash(d.pieces[d.offsets[i]:end])
+	}
+}
+
+// writeDash appends a single dash to the output path.
+func (d *dasher) writeDash(pieces []dashCurve) {
+	d.out.MoveTo(pieces[0].p[0])
+	for _, c := range pieces {
+		switch c.n {
+		case 2:
+			d.out.LineTo(c.p[1])
+		case 3:
+			d.out.QuadTo(c.p[1], c.p[2])
+		case 4:
+			d.out.CubeTo(c.p[1], c.p[2], c.p[3])
+		}
+	}
+}
+
+// dashCurve is a line segment or a quadratic or cubic Bézier curve, with
+// a table for arc length computations.
+type dashCurve struct {
+	p   [4]vec.Vec2
+	n   int                          // number of control points, 2–4
+	cum [dashLengthSteps + 1]float64 // arc length from t=0 to t=k/dashLengthSteps
+}
+
+// dashLengthSteps is the number of intervals used to tabulate the arc
+// length of a curve.
+const dashLengthSteps = 16
+
+func newDashCurve(pts ...vec.Vec2) dashCurve {
+	c := dashCurve{n: len(pts)}
+	copy(c.p[:], pts)
+	for k := 1; k <= dashLengthSteps; k++ {
+		t0 := float64(k-1) / dashLengthSteps
+		t1 := float64(k) / dashLengthSteps
+		c.cum[k] = c.cum[k-1] + c.lengthBetween(t0, t1)
+	}
+	return c
+}
+
+// length returns the total arc length of the curve.
+func (c *dashCurve) length() float64 {
+	return c.cum[dashLengthSteps]
+}
+
+// point evaluates the curve at parameter t.
+func (c *dashCurve) point(t float64) vec.Vec2 {
+	var q [4]vec.Vec2
+	copy(q[:c.n], c.p[:c.n])
+	for k := c.n - 1; k > 0; k-- {
+		for i := range k {
+			q[i] = q[i].Add(q[i+1].Sub(q[i]).Mul(t))
+		}
+	}
+	return q[0]
+}
+
+// speed returns the length of the derivative at parameter t.
+func (c *dashCurve) speed(t float64) float64 {
+	var q [3]vec.Vec2
+	m := c.n - 1
+	for i := range m {
+		q[i] = c.p[i+1].Sub(c.p[i]).Mul(float64(m))
+	}
+	for k := m - 1; k > 0; k-- {
+		for i := range k {
+			q[i] = q[i].Add(q[i+1].Sub(q[i]).Mul(t))
+		}
+	}
+	return q[0].Length()
+}
+
+// lengthBetween computes the arc length between t0 and t1 using 5-point
+// Gauss-Legendre quadrature.
+func (c *dashCurve) lengthBetween(t0, t1 float64) float64 {
+	if c.n == 2 {
+		return c.p[1].Sub(c.p[0]).Length() * (t1 - t0)
+	}
+	nodes := [5]float64{0, -0.5384693101056831, 0.5384693101056831, -0.9061798459386640, 0.9061798459386640}
+	weights := [5]float64{0.5688888888888889, 0.4786286704993665, 0.4786286704993665, 0.2369268850561891, 0.2369268850561891}
+	mid, half := (t0+t1)/2, (t1-t0)/2
+	sum := 0.0
+	for i, x := range nodes {
+		sum += weights[i] * c.speed(mid+half*x)
+	}
+	return sum * half
+}
+
+// paramAt returns the parameter t at which the arc length from the start
+// of the curve equals s.
+func (c *dashCurve) paramAt(s float64) float64 {
+	L := c.length()
+	if s <= 0 {
+		return 0
+	}
+	if s >= L {
+		return 1
+	}
+	if c.n == 2 {
+		return s / L
+	}
+
+	// find the tabulated interval, then use Newton's method safeguarded
+	// by bisection
+	k, _ := slices.BinarySearch(c.cum[:], s)
+	k = max(k-1, 0)
+	lo := float64(k) / dashLengthSteps
+	hi := float64(k+1) / dashLengthSteps
+	start, base := lo, c.cum[k]
+	t := lo + (hi-lo)*(s-base)/(c.cum[k+1]-base)
+	for range 20 {
+		f := base + c.lengthBetween(start, t) - s
+		if math.Abs(f) <= 1e-12*L {
+			break
+		}
+		if f > 0 {
+			hi = t
+		} else {
+			lo = t
+		}
+		next := t - f/c.speed(t)
+		if !(next > lo && next < hi) {
+			next = (lo + hi) / 2
+		}
+		t = next
+	}
+	return t
+}
+
+// sub returns the part of the curve between parameters t0 and t1.
+func (c *dashCurve) sub(t0, t1 float64) dashCurve {
+	var q [4]vec.Vec2
+	copy(q[:c.n], c.p[:c.n])
+	if t1 < 1 {
+		q = splitLeft(q, c.n, t1)
+		if t1 > 0 {
+			t0 /= t1
+		}
+	}
+	if t0 > 0 {
+		q = splitRight(q, c.n, t0)
+	}
+	return newDashCurve(q[:c.n]...)
+}
+
+// splitLeft returns the control points of the curve restricted to [0, t].
+func splitLeft(p [4]vec.Vec2, n int, t float64) [4]vec.Vec2 {
+	var res [4]vec.Vec2
+	for k := range n {
+		res[k] = p[0]
+		for i := range n - 1 - k {
+			p[i] = p[i].Add(p[i+1].Sub(p[i]).Mul(t))
+		}
+	}
+	return res
+}
+
+// splitRight returns the control points of the curve restricted to [t, 1].
+func splitRight(p [4]vec.Vec2, n int, t float64) [4]vec.Vec2 {
+	var res [4]vec.Vec2
+	for k := range n {
+		res[n-1-k] = p[n-1-k]
+		for i := range n - 1 - k {
+			p[i] = p[i].Add(p[i+1].Sub(p[i]).Mul(t))
+		}
+	}
+	return res
+}
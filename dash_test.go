@@ -0,0 +1,181 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package raster
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"seehuhn.de/go/geom/path"
+	"seehuhn.de/go/geom/rect"
+	"seehuhn.de/go/geom/vec"
+)
+
+func TestDashPathLine(t *testing.T) {
+	r := NewRasterizer(rect.Rect{URx: 10, URy: 10})
+	r.Dash = []float64{2, 1}
+	p := (&path.Data{}).MoveTo(vec.Vec2{X: 0, Y: 0}).LineTo(vec.Vec2{X: 10, Y: 0})
+
+	got := r.DashPath(p.Iter())
+	want := &path.Data{}
+	for _, x := range []float64{0, 3, 6, 9} {
+		want.MoveTo(vec.Vec2{X: x}).LineTo(vec.Vec2{X: min(x+2, 10)})
+	}
+	if !slices.Equal(got.Cmds, want.Cmds) {
+		t.Fatalf("got commands %v, want %v", got.Cmds, want.Cmds)
+	}
+	for i, pt := range got.Coords {
+		if pt.Sub(want.Coords[i]).Length() > 1e-9 {
+			t.Errorf("point %d = %v, want %v", i, pt, want.Coords[i])
+		}
+	}
+
+	// without a dash pattern, the path is copied
+	r.Dash = nil
+	got = r.DashPath(p.Iter())
+	if !slices.Equal(got.Cmds, p.Cmds) || !slices.Equal(got.Coords, p.Coords) {
+		t.Errorf("undashed: got %v %v", got.Cmds, got.Coords)
+	}
+}
+
+func TestDashPathClosed(t *testing.T) {
+	r := NewRasterizer(rect.Rect{URx: 10, URy: 10})
+	r.Dash = []float64{6, 2}
+	r.DashPhase = 3
+	square := rectPath(0, 0, 10, 10)
+
+	// Along the perimeter of length 40, the dashes are [0,3], [5,11],
+	// [13,19], [21,27], [29,35] and [37,40]. The first and last dash
+	// are joined.
+	got := r.DashPath(square.Iter())
+	var starts []vec.Vec2
+	for cmd, pts := range got.Iter() {
+		if cmd == path.CmdMoveTo {
+			starts = append(starts, pts[0])
+		}
+		if cmd == path.CmdClose {
+			t.Error("dashed path contains ClosePath")
+		}
+	}
+	want := []vec.Vec2{{X: 5}, {X: 10, Y: 3}, {X: 9, Y: 10}, {X: 1, Y: 10}, {X: 0, Y: 3}}
+	if len(starts) != len(want) {
+		t.Fatalf("got %d dashes, want %d: %v", len(starts), len(want), starts)
+	}
+	for k := range want {
+		if starts[k].Sub(want[k]).Length() > 1e-9 {
+			t.Errorf("dash %d starts at %v, want %v", k, starts[k], want[k])
+		}
+	}
+	last := got.Coords[len(got.Coords)-1]
+	if last.Sub(vec.Vec2{X: 3}).Length() > 1e-9 {
+		t.Errorf("joined dash ends at %v, want (3,0)", last)
+	}
+}
+
+func TestDashPathCurve(t *testing.T) {
+	r := NewRasterizer(rect.Rect{URx: 100, URy: 100})
+	r.Dash = []float64{5, 3}
+	orig := newDashCurve(vec.Vec2{X: 0, Y: 0}, vec.Vec2{X: 30, Y: 60}, vec.Vec2{X: 70, Y: -20}, vec.Vec2{X: 100, Y: 40})
+	p := (&path.Data{}).MoveTo(orig.p[0]).CubeTo(orig.p[1], orig.p[2], orig.p[3])
+
+	got := r.DashPath(p.Iter())
+	total := 0.0
+	var current vec.Vec2
+	for cmd, pts := range got.Iter() {
+		switch cmd {
+		case path.CmdMoveTo:
+			current = pts[0]
+		case path.CmdCubeTo:
+			c := newDashCurve(current, pts[0], pts[1], pts[2])
+			if l := c.length(); l > 5+1e-6 {
+				t.Errorf("dash of length %g", l)
+			}
+			total += c.length()
+			current = pts[2]
+		default:
+			t.Fatalf("unexpected command %v", cmd)
+		}
+	}
+
+	// compute the expected "on" length from the pattern
+	L := orig.length()
+	want := 0.0
+	for s := 0.0; s < L; s += 8 {
+		want += min(5, L-s)
+	}
+	if math.Abs(total-want) > 1e-6 {
+		t.Errorf("total dash length %g, want %g", total, want)
+	}
+
+	// the second dash starts 8 units along the curve
+	var starts []vec.Vec2
+	for cmd, pts := range got.Iter() {
+		if cmd == path.CmdMoveTo {
+			starts = append(starts, pts[0])
+		}
+	}
+	tStart := orig.paramAt(8)
+	if d := starts[1].Sub(orig.point(tStart)).Length(); d > 1e-9 {
+		t.Errorf("second dash is %g away from the curve", d)
+	}
+	if got := orig.lengthBetween(0, tStart); math.Abs(got-8) > 1e-6 {
+		t.Errorf("arc length to second dash %g, want 8", got)
+	}
+}
+
+func TestDashPathStroke(t *testing.T) {
+	// Stroking the dashed path gives the same result as a dashed stroke.
+	const size = 50
+	p := (&path.Data{}).
+		MoveTo(vec.Vec2{X: 5, Y: 5}).
+		LineTo(vec.Vec2{X: 45, Y: 10}).
+		LineTo(vec.Vec2{X: 20, Y: 40}).
+		Close()
+	r := NewRasterizer(rect.Rect{URx: size, URy: size})
+	r.Width = 2
+	r.Dash = []float64{7, 4, 1, 4}
+	r.DashPhase = 2
+	want := renderStroke(r, p, size, size)
+
+	dashed := r.DashPath(p.Iter())
+	r.Dash = nil
+	got := renderStroke(r, dashed, size, size)
+	for i := range want {
+		if math.Abs(float64(got[i]-want[i])) > 1e-4 {
+			t.Errorf("pixel (%d,%d) = %g, want %g", i%size, i/size, got[i], want[i])
+		}
+	}
+}
+
+func TestDashPathLimit(t *testing.T) {
+	p := (&path.Data{}).MoveTo(vec.Vec2{X: 0, Y: 0}).LineTo(vec.Vec2{X: 100, Y: 0})
+	r := NewRasterizer(rect.Rect{URx: 100, URy: 100})
+	r.Limits = Limits{MaxDashes: 100}
+
+	r.Dash = []float64{1}
+	if got := len(r.DashPath(p.Iter()).Cmds); got != 100 {
+		t.Errorf("got %d commands, want 100", got)
+	}
+
+	// Too many dashes give the undashed path.
+	r.Dash = []float64{0.1}
+	got := r.DashPath(p.Iter())
+	if !slices.Equal(got.Cmds, p.Cmds) || !slices.Equal(got.Coords, p.Coords) {
+		t.Errorf("got %v, want %v", got, p)
+	}
+}
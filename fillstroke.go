@@ -0,0 +1,121 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package raster
+
+import (
+	"slices"
+
+	"seehuhn.de/go/geom/path"
+)
+
+// FillStroke fills the path using the given rule and then strokes it, as
+// the PDF operators B, B*, b and b* do. The path is flattened only once,
+// and the flattened segments are used both for the fill and for the
+// stroke. Subpaths which are not closed are closed for filling, as in
+// FillNonZero and FillEvenOdd.
+//
+// The coverage of the fill is passed to emitFill, and then the coverage of
+// the stroke to emitStroke. If emitStroke is nil, fill and stroke are
+// treated as a single object instead: emitFill then receives the coverage
+// of the union of the filled and stroked areas, once per row. This is
+// needed when painting into knockout groups, and for transparency. The
+// merged coverage of a pixel is the larger of the fill and stroke
+// coverage, which is exact when one of the two areas contains the other
+// within the pixel.
+//
+// In both cases, the slice argument of the callbacks is valid only during
+// the call.
+func (r *Rasterizer) FillStroke(p path.Path, rule FillRule, emitFill, emitStroke func(y, xMin int, coverage []float32)) {
+	r.flattenPath(p)
+
+	if emitStroke != nil {
+		r.fillSegments(rule, emitFill)
+		r.strokeSegments()
+		r.fillStrokeOutlines(emitStroke)
+		return
+	}
+
+	// Keep the rows of the fill, and merge them into the rows of the
+	// stroke as these are generated.
+	r.mergeRows = r.mergeRows[:0]
+	r.mergeCoverage = r.mergeCoverage[:0]
+	r.fillSegments(rule, func(y, xMin int, coverage []float32) {
+		start := len(r.mergeCoverage)
+		r.mergeCoverage = append(r.mergeCoverage, coverage...)
+		r.mergeRows = append(r.mergeRows, storedRow{y: y, xMin: xMin, start: start, end: len(r.mergeCoverage)})
+	})
+
+	next := 0 // the first fill row not yet emitted
+	r.strokeSegments()
+	r.fillStrokeOutlines(func(y, xMin int, coverage []float32) {
+		for next < len(r.mergeRows) && r.mergeRows[next].y < y {
+			row := r.mergeRows[next]
+			emitFill(row.y, row.xMin, r.mergeCoverage[row.start:row.end])
+			next++
+		}
+		if next == len(r.mergeRows) || r.mergeRows[next].y > y {
+			emitFill(y, xMin, coverage)
+			return
+		}
+
+		row := r.mergeRows[next]
+		next++
+		fill := r.mergeCoverage[row.start:row.end]
+		x0 := min(xMin, row.xMin)
+		x1 := max(xMin+len(coverage), row.xMin+len(fill))
+		buf := slices.Grow(r.mergeRow[:0], x1-x0)[:x1-x0]
+		clear(buf)
+		copy(buf[row.xMin-x0:], fill)
+		for i, c := range coverage {
+			buf[xMin-x0+i] = max(buf[xMin-x0+i], c)
+		}
+		r.mergeRow = buf
+		emitFill(y, x0, buf)
+	})
+	for _, row := range r.mergeRows[next:] {
+		emitFill(row.y, row.xMin, r.mergeCoverage[row.start:row.end])
+	}
+}
+
+// fillSegments fills the path previously flattened by flattenPath, using
+// the given rule. Open subpaths are closed.
+func (r *Rasterizer) fillSegments(rule FillRule, emit func(y, xMin int, coverage []float32)) {
+	r.startEdges(true)
+
+	for i, start := range r.segsOffsets {
+		end := len(r.segs)
+		if i+1 < len(r.segsOffsets) {
+			end = r.segsOffsets[i+1]
+		}
+		segs := r.segs[start:end]
+		for j := range segs {
+			r.addEdge(segs[j].A, segs[j].B)
+		}
+		if first, last := segs[0].A, segs[len(segs)-1].B; first != last {
+			r.addEdge(last, first)
+		}
+	}
+	if len(r.edges) == 0 {
+		return
+	}
+
+	xMin, xMax, yMin, yMax, ok := r.edgeBounds()
+	if !ok {
+		return
+	}
+	r.fillEdges(xMin, xMax, yMin, yMax, rule, emit)
+}
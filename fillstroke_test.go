@@ -0,0 +1,126 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package raster
+
+import (
+	"testing"
+
+	"seehuhn.de/go/geom/matrix"
+	"seehuhn.de/go/geom/path"
+	"seehuhn.de/go/geom/rect"
+	"seehuhn.de/go/geom/vec"
+)
+
+// fillStrokeCases returns paths and rasterizer settings for comparing
+// FillStroke with separate calls to fill and stroke.
+func fillStrokeCases() []struct {
+	name  string
+	p     *path.Data
+	setup func(r *Rasterizer)
+} {
+	open := (&path.Data{}).
+		MoveTo(vec.Vec2{X: 5, Y: 5}).
+		CubeTo(vec.Vec2{X: 40, Y: 0}, vec.Vec2{X: 40, Y: 40}, vec.Vec2{X: 10, Y: 35}).
+		LineTo(vec.Vec2{X: 20, Y: 20})
+	return []struct {
+		name  string
+		p     *path.Data
+		setup func(r *Rasterizer)
+	}{
+		{"star", starPath(7, 40), func(r *Rasterizer) { r.Width = 3 }},
+		{"open curve", open, func(r *Rasterizer) { r.Width = 2.5 }},
+		{"dashed", open, func(r *Rasterizer) { r.Width = 2; r.Dash = []float64{4, 3} }},
+		{"hairline", starPath(5, 20), func(r *Rasterizer) { r.Width = 0; r.CTM = matrix.Scale(2, 2) }},
+		{"adjusted", rectPath(5.3, 5.3, 30.3, 20.7), func(r *Rasterizer) { r.Width = 0.5; r.StrokeAdjust = true }},
+	}
+}
+
+func TestFillStroke(t *testing.T) {
+	const size = 40
+
+	for _, c := range fillStrokeCases() {
+		for _, rule := range []FillRule{NonZero, EvenOdd} {
+			r := NewRasterizer(rect.Rect{URx: size, URy: size})
+			c.setup(r)
+
+			wantFill := make([]float32, size*size)
+			r.fill(c.p.Iter(), rule, func(y, xMin int, coverage []float32) {
+				copy(wantFill[y*size+xMin:], coverage)
+			})
+			wantStroke := renderStroke(r, c.p, size, size)
+
+			gotFill := make([]float32, size*size)
+			gotStroke := make([]float32, size*size)
+			fillDone := false
+			r.FillStroke(c.p.Iter(), rule, func(y, xMin int, coverage []float32) {
+				if fillDone {
+					t.Fatalf("%s: fill row %d emitted after stroke", c.name, y)
+				}
+				copy(gotFill[y*size+xMin:], coverage)
+			}, func(y, xMin int, coverage []float32) {
+				fillDone = true
+				copy(gotStroke[y*size+xMin:], coverage)
+			})
+
+			for i := range wantFill {
+				if !closeTo(gotFill[i], wantFill[i]) {
+					t.Errorf("%s, rule %d: fill pixel (%d,%d) = %g, want %g",
+						c.name, rule, i%size, i/size, gotFill[i], wantFill[i])
+				}
+				if !closeTo(gotStroke[i], wantStroke[i]) {
+					t.Errorf("%s, rule %d: stroke pixel (%d,%d) = %g, want %g",
+						c.name, rule, i%size, i/size, gotStroke[i], wantStroke[i])
+				}
+			}
+		}
+	}
+}
+
+func TestFillStrokeMerged(t *testing.T) {
+	const size = 40
+
+	for _, c := range fillStrokeCases() {
+		r := NewRasterizer(rect.Rect{URx: size, URy: size})
+		c.setup(r)
+
+		want := make([]float32, size*size)
+		r.FillStroke(c.p.Iter(), NonZero, func(y, xMin int, coverage []float32) {
+			copy(want[y*size+xMin:], coverage)
+		}, func(y, xMin int, coverage []float32) {
+			for i, v := range coverage {
+				want[y*size+xMin+i] = max(want[y*size+xMin+i], v)
+			}
+		})
+
+		got := make([]float32, size*size)
+		lastY := -1
+		r.FillStroke(c.p.Iter(), NonZero, func(y, xMin int, coverage []float32) {
+			if y <= lastY {
+				t.Errorf("%s: row %d emitted after row %d", c.name, y, lastY)
+			}
+			lastY = y
+			copy(got[y*size+xMin:], coverage)
+		}, nil)
+
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("%s: pixel (%d,%d) = %g, want %g",
+					c.name, i%size, i/size, got[i], want[i])
+			}
+		}
+	}
+}
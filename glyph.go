@@ -0,0 +1,216 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package raster
+
+import (
+	"math"
+
+	"seehuhn.de/go/geom/matrix"
+	"seehuhn.de/go/geom/path"
+	"seehuhn.de/go/geom/rect"
+	"seehuhn.de/go/sfnt"
+	"seehuhn.de/go/sfnt/glyph"
+)
+
+// GlyphCache stores the coverage of rasterized glyphs, so that glyphs which
+// occur repeatedly on a page are flattened and rasterized only once.
+//
+// Glyphs are cached per font, glyph ID, glyph-to-device transformation
+// (excluding the translation), Flatness and sub-pixel position. Glyph positions are
+// rounded to 1/glyphSubpixelSteps of a pixel in each direction.
+//
+// A GlyphCache is not safe for concurrent use.
+type GlyphCache struct {
+	// MaxGlyphs limits the number of cached glyph bitmaps. When the limit
+	// is reached, the cache is cleared. Zero means no limit.
+	MaxGlyphs int
+
+	glyphs map[glyphKey]*glyphBitmap
+	r      *Rasterizer // renders glyphs into bitmaps, without clipping
+}
+
+// glyphKey identifies a cached glyph bitmap.
+type glyphKey struct {
+	font     *sfnt.Font
+	gid      glyph.ID
+	m        [4]float64 // linear part of the glyph space to device space map
+	flatness float64    // Flatness used for rendering
+	dx, dy   uint8      // quantized sub-pixel offset
+}
+
+// glyphBitmap holds the coverage rows of a rendered glyph, relative to the
+// integer part of the glyph origin in device space.
+type glyphBitmap struct {
+	rows     []storedRow
+	coverage []float32 // storage for all rows, contiguous
+}
+
+// storedRow is a row of coverage values which has been copied into a
+// contiguous buffer, for delivery at a later time.
+type storedRow struct {
+	y, xMin    int
+	start, end int
+}
+
+// glyphOutlines is implemented by the glyph outlines of sfnt fonts
+// (TrueType and CFF).
+type glyphOutlines interface {
+	Path(gid glyph.ID) path.Path
+}
+
+const (
+	// glyphSubpixelSteps is the number of sub-pixel positions per pixel, in
+	// each direction, for which separate glyph bitmaps are cached.
+	glyphSubpixelSteps = 4
+
+	// defaultMaxGlyphs is the default value of GlyphCache.MaxGlyphs.
+	defaultMaxGlyphs = 4096
+)
+
+// glyphClip is the clip rectangle used when rendering glyph bitmaps. Glyphs
+// are rendered near the device space origin, so this leaves ample room.
+var glyphClip = rect.Rect{LLx: -1 << 20, LLy: -1 << 20, URx: 1 << 20, URy: 1 << 20}
+
+// NewGlyphCache returns an empty glyph cache.
+func NewGlyphCache() *GlyphCache {
+	return &GlyphCache{
+		MaxGlyphs: defaultMaxGlyphs,
+		glyphs:    make(map[glyphKey]*glyphBitmap),
+		r:         NewRasterizer(glyphClip),
+	}
+}
+
+// Reset removes all glyphs from the cache.
+func (c *GlyphCache) Reset() {
+	clear(c.glyphs)
+}
+
+// FillGlyph fills a glyph of an sfnt font, using the nonzero winding rule.
+//
+// The glyph is scaled by the font matrix and the font size, and placed with
+// its origin at the origin of user space; CTM maps user space to device
+// space. For PDF text, user space here is the text space of the glyph,
+// i.e. the CTM includes the text matrix and the text rise.
+//
+// Rendered glyphs are stored in cache and reused by later calls. Clip and
+// the clip path stack are applied to the cached coverage, so that the same
+// bitmap can be used at different positions and under different clip
+// paths.
+func (r *Rasterizer) FillGlyph(cache *GlyphCache, f *sfnt.Font, gid glyph.ID, size float64, emit func(y, xMin int, coverage []float32)) {
+	m := matrix.Matrix(f.FontMatrix).Mul(matrix.Scale(size, size)).Mul(r.CTM)
+	if m[0]*m[3]-m[1]*m[2] == 0 {
+		return
+	}
+
+	ix, dx, ok1 := splitPosition(m[4])
+	iy, dy, ok2 := splitPosition(m[5])
+	if !ok1 || !ok2 {
+		return
+	}
+
+	key := glyphKey{
+		font:     f,
+		gid:      gid,
+		m:        [4]float64{m[0], m[1], m[2], m[3]},
+		flatness: r.Flatness,
+		dx:       dx,
+		dy:       dy,
+	}
+	bm, ok := cache.glyphs[key]
+	if !ok {
+		outlines, ok := f.Outlines.(glyphOutlines)
+		if !ok {
+			return
+		}
+		gm := m
+		gm[4] = float64(dx) / glyphSubpixelSteps
+		gm[5] = float64(dy) / glyphSubpixelSteps
+		bm = cache.render(outlines.Path(gid), gm, r.Flatness)
+
+		if cache.MaxGlyphs > 0 && len(cache.glyphs) >= cache.MaxGlyphs {
+			clear(cache.glyphs)
+		}
+		cache.glyphs[key] = bm
+	}
+
+	xMin, xMax, yMin, yMax := r.clipBounds()
+	for _, row := range bm.rows {
+		y := row.y + iy
+		if y < yMin || y >= yMax {
+			continue
+		}
+		coverage := bm.coverage[row.start:row.end]
+		x := row.xMin + ix
+		if x < xMin {
+			skip := xMin - x
+			if skip >= len(coverage) {
+				continue
+			}
+			coverage = coverage[skip:]
+			x = xMin
+		}
+		if x+len(coverage) > xMax {
+			if x >= xMax {
+				continue
+			}
+			coverage = coverage[:xMax-x]
+		}
+
+		// emitRow modifies the coverage in place, so work on a copy
+		if cap(r.cover) < len(coverage) {
+			r.cover = make([]float32, len(coverage))
+		}
+		buf := r.cover[:len(coverage)]
+		copy(buf, coverage)
+		r.emitRow(y, x, buf, emit)
+	}
+}
+
+// render rasterizes a glyph outline into a new bitmap. The matrix m maps
+// glyph space to device space, with only the sub-pixel offset as the
+// translation.
+func (c *GlyphCache) render(p path.Path, m matrix.Matrix, flatness float64) *glyphBitmap {
+	bm := &glyphBitmap{}
+	c.r.CTM = m
+	c.r.Flatness = flatness
+	c.r.FillNonZero(p, func(y, xMin int, coverage []float32) {
+		start := len(bm.coverage)
+		bm.coverage = append(bm.coverage, coverage...)
+		bm.rows = append(bm.rows, storedRow{
+			y:     y,
+			xMin:  xMin,
+			start: start,
+			end:   len(bm.coverage),
+		})
+	})
+	return bm
+}
+
+// splitPosition splits a device coordinate into an integer pixel position
+// and a quantized sub-pixel offset.
+func splitPosition(v float64) (pixel int, sub uint8, ok bool) {
+	if math.IsNaN(v) || math.Abs(v) > 1<<30 {
+		return 0, 0, false
+	}
+	f := math.Floor(v)
+	q := math.Round((v - f) * glyphSubpixelSteps)
+	if q >= glyphSubpixelSteps {
+		f++
+		q = 0
+	}
+	return int(f), uint8(q), true
+}
@@ -0,0 +1,190 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package raster
+
+import (
+	"testing"
+
+	"seehuhn.de/go/geom/matrix"
+	"seehuhn.de/go/geom/path"
+	"seehuhn.de/go/geom/rect"
+	"seehuhn.de/go/geom/vec"
+	"seehuhn.de/go/sfnt"
+	"seehuhn.de/go/sfnt/glyph"
+)
+
+// testOutlines provides glyph outlines for testing, in a 1000 unit em
+// square. Glyph 1 is a triangle with one curved side; all other glyphs
+// are empty.
+type testOutlines struct {
+	calls int
+}
+
+func (o *testOutlines) Path(gid glyph.ID) path.Path {
+	o.calls++
+	if gid != 1 {
+		return (&path.Data{}).Iter()
+	}
+	return testGlyph().Iter()
+}
+
+func (o *testOutlines) NumGlyphs() int {
+	return 2
+}
+
+func (o *testOutlines) IsBlank(gid glyph.ID) bool {
+	return gid != 1
+}
+
+func (o *testOutlines) GlyphBBox(m matrix.Matrix, gid glyph.ID) rect.Rect {
+	if gid != 1 {
+		return rect.Rect{}
+	}
+	var bbox rect.Rect
+	first := true
+	for _, pt := range []vec.Vec2{{X: 100, Y: 0}, {X: 900, Y: 0}, {X: 500, Y: 300}, {X: 500, Y: 700}} {
+		x, y := m.Apply(pt.X, pt.Y)
+		if first {
+			bbox = rect.Rect{LLx: x, LLy: y, URx: x, URy: y}
+			first = false
+		} else {
+			bbox.Add(x, y)
+		}
+	}
+	return bbox
+}
+
+func (o *testOutlines) GlyphBBoxPDF(m matrix.Matrix, gid glyph.ID) rect.Rect {
+	// The em square has 1000 units, so design units are PDF glyph space
+	// units.
+	return o.GlyphBBox(m, gid)
+}
+
+func testGlyph() *path.Data {
+	return (&path.Data{}).
+		MoveTo(vec.Vec2{X: 100, Y: 0}).
+		LineTo(vec.Vec2{X: 900, Y: 0}).
+		QuadTo(vec.Vec2{X: 500, Y: 300}, vec.Vec2{X: 500, Y: 700}).
+		Close()
+}
+
+func testFont() (*sfnt.Font, *testOutlines) {
+	o := &testOutlines{}
+	f := &sfnt.Font{
+		FontMatrix: matrix.Scale(0.001, 0.001),
+		Outlines:   o,
+	}
+	return f, o
+}
+
+// glyphCoverage renders glyph 1 of f at size 10 with the given CTM and
+// returns the coverage as a 20×20 buffer.
+func glyphCoverage(r *Rasterizer, cache *GlyphCache, f *sfnt.Font, ctm matrix.Matrix) []float32 {
+	buf := make([]float32, 20*20)
+	r.CTM = ctm
+	r.FillGlyph(cache, f, 1, 10, func(y, xMin int, coverage []float32) {
+		copy(buf[y*20+xMin:], coverage)
+	})
+	return buf
+}
+
+func TestFillGlyph(t *testing.T) {
+	f, _ := testFont()
+	cache := NewGlyphCache()
+	r := NewRasterizer(rect.Rect{URx: 20, URy: 20})
+
+	// Offsets are multiples of the sub-pixel step, so the result must be
+	// the same as filling the outline directly.
+	for _, pos := range []vec.Vec2{{X: 3, Y: 4}, {X: 5.25, Y: 2.75}, {X: 0.5, Y: 9}} {
+		ctm := matrix.Translate(pos.X, pos.Y)
+		got := glyphCoverage(r, cache, f, ctm)
+
+		r.CTM = matrix.Scale(0.01, 0.01).Mul(ctm)
+		want := renderCoverage(r, testGlyph(), 20, 20)
+
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("%v: pixel (%d,%d) = %g, want %g",
+					pos, i%20, i/20, got[i], want[i])
+			}
+		}
+	}
+}
+
+func TestGlyphCache(t *testing.T) {
+	f, o := testFont()
+	cache := NewGlyphCache()
+	r := NewRasterizer(rect.Rect{URx: 20, URy: 20})
+
+	glyphCoverage(r, cache, f, matrix.Translate(1, 1))
+	glyphCoverage(r, cache, f, matrix.Translate(7, 3))
+	glyphCoverage(r, cache, f, matrix.Translate(4.01, 9))
+	if o.calls != 1 {
+		t.Errorf("same sub-pixel position: %d outline lookups, want 1", o.calls)
+	}
+
+	glyphCoverage(r, cache, f, matrix.Translate(4.5, 9))
+	glyphCoverage(r, cache, f, matrix.Scale(2, 2))
+	if o.calls != 3 {
+		t.Errorf("new position and size: %d outline lookups, want 3", o.calls)
+	}
+
+	// A glyph rendered at a different flatness is not reused.
+	r.Flatness = 0.1
+	glyphCoverage(r, cache, f, matrix.Translate(1, 1))
+	r.Flatness = defaultFlatness
+	if o.calls != 4 {
+		t.Errorf("new flatness: %d outline lookups, want 4", o.calls)
+	}
+
+	cache.MaxGlyphs = 4
+	glyphCoverage(r, cache, f, matrix.Translate(0.25, 0))
+	if len(cache.glyphs) != 1 {
+		t.Errorf("cache not cleared at limit: %d glyphs", len(cache.glyphs))
+	}
+
+	cache.Reset()
+	glyphCoverage(r, cache, f, matrix.Translate(1, 1))
+	if o.calls != 6 {
+		t.Errorf("after Reset: %d outline lookups, want 6", o.calls)
+	}
+}
+
+func TestFillGlyphClip(t *testing.T) {
+	f, _ := testFont()
+	cache := NewGlyphCache()
+	r := NewRasterizer(rect.Rect{URx: 20, URy: 20})
+
+	full := glyphCoverage(r, cache, f, matrix.Translate(5, 5))
+
+	r.Clip = rect.Rect{LLx: 0, LLy: 0, URx: 10, URy: 20}
+	r.CTM = matrix.Identity
+	r.PushClipPath(rectPath(0, 0, 20, 8).Iter(), NonZero)
+	clipped := glyphCoverage(r, cache, f, matrix.Translate(5, 5))
+
+	for y := range 20 {
+		for x := range 20 {
+			want := full[y*20+x]
+			if x >= 10 || y >= 8 {
+				want = 0
+			}
+			if got := clipped[y*20+x]; got != want {
+				t.Errorf("pixel (%d,%d) = %g, want %g", x, y, got, want)
+			}
+		}
+	}
+}
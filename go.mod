@@ -6,14 +6,15 @@ require (
 	golang.org/x/image v0.28.0
 	seehuhn.de/go/geom v0.7.0
 	seehuhn.de/go/pdf v0.7.0
+	seehuhn.de/go/sfnt v0.7.0
 )
 
 require (
 	github.com/xdg-go/stringprep v1.0.4 // indirect
 	golang.org/x/exp v0.0.0-20250606033433-dcc06ee1d476 // indirect
 	golang.org/x/text v0.26.0 // indirect
+	seehuhn.de/go/dag v0.0.0-20230612165854-b02059e84ec5 // indirect
 	seehuhn.de/go/icc v0.7.0 // indirect
 	seehuhn.de/go/postscript v0.7.0 // indirect
-	seehuhn.de/go/sfnt v0.7.0 // indirect
 	seehuhn.de/go/xmp v0.7.0 // indirect
 )
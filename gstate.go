@@ -0,0 +1,125 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package raster
+
+import (
+	"seehuhn.de/go/geom/matrix"
+	"seehuhn.de/go/geom/rect"
+	"seehuhn.de/go/pdf/graphics"
+)
+
+// GraphicsState holds the parameters of a Rasterizer which form part of
+// the PDF and PostScript graphics state. The fields have the same meaning
+// as the corresponding fields of Rasterizer.
+//
+// The clip paths set by PushClipPath are not included, since they are
+// stored inside the Rasterizer; they are covered by Save and Restore.
+type GraphicsState struct {
+	CTM          matrix.Matrix
+	Clip         rect.Rect
+	Flatness     float64
+	Width        float64
+	Cap          graphics.LineCapStyle
+	Join         graphics.LineJoinStyle
+	MiterLimit   float64
+	Dash         []float64
+	DashPhase    float64
+	StrokeAdjust bool
+}
+
+// savedState is an entry of the stack used by Save and Restore.
+type savedState struct {
+	GraphicsState
+	clipDepth int
+}
+
+// GetState copies the graphics state parameters of r into s. The dash
+// pattern is copied into the existing storage of s.Dash where possible,
+// so that s can be reused without allocations.
+func (r *Rasterizer) GetState(s *GraphicsState) {
+	dash := s.Dash
+	*s = GraphicsState{
+		CTM:          r.CTM,
+		Clip:         r.Clip,
+		Flatness:     r.Flatness,
+		Width:        r.Width,
+		Cap:          r.Cap,
+		Join:         r.Join,
+		MiterLimit:   r.MiterLimit,
+		DashPhase:    r.DashPhase,
+		StrokeAdjust: r.StrokeAdjust,
+	}
+	if r.Dash != nil {
+		s.Dash = append(dash[:0], r.Dash...)
+	}
+}
+
+// SetState sets the graphics state parameters of r from s. The dash
+// pattern is copied into storage owned by r, so s may be modified
+// afterwards. The clip paths are not changed.
+func (r *Rasterizer) SetState(s *GraphicsState) {
+	r.CTM = s.CTM
+	r.Clip = s.Clip
+	r.Flatness = s.Flatness
+	r.Width = s.Width
+	r.Cap = s.Cap
+	r.Join = s.Join
+	r.MiterLimit = s.MiterLimit
+	r.DashPhase = s.DashPhase
+	r.StrokeAdjust = s.StrokeAdjust
+	if s.Dash == nil {
+		r.Dash = nil
+	} else {
+		r.dashBuf = append(r.dashBuf[:0], s.Dash...)
+		r.Dash = r.dashBuf
+	}
+}
+
+// Save pushes a copy of the current graphics state, including the current
+// clip paths, onto a stack. This corresponds to the PDF operator q and to
+// PostScript gsave. Once the stack has grown to its maximum depth, Save
+// and Restore do not allocate.
+func (r *Rasterizer) Save() {
+	if r.saveDepth == len(r.saveStack) {
+		r.saveStack = append(r.saveStack, savedState{})
+	}
+	s := &r.saveStack[r.saveDepth]
+	r.GetState(&s.GraphicsState)
+	s.clipDepth = r.clipDepth
+	r.saveDepth++
+}
+
+// Restore sets the graphics state to the one most recently saved by Save,
+// and removes it from the stack. Clip paths pushed since the
+// corresponding call to Save are removed. This corresponds to the PDF
+// operator Q and to PostScript grestore. If the stack is empty, Restore
+// does nothing.
+func (r *Rasterizer) Restore() {
+	if r.saveDepth == 0 {
+		return
+	}
+	r.saveDepth--
+	s := &r.saveStack[r.saveDepth]
+	r.SetState(&s.GraphicsState)
+	r.clipDepth = s.clipDepth
+}
+
+// SaveDepth returns the number of graphics states saved by Save which
+// have not yet been restored.
+func (r *Rasterizer) SaveDepth() int {
+	return r.saveDepth
+}
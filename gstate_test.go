@@ -0,0 +1,152 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package raster
+
+import (
+	"reflect"
+	"testing"
+
+	"seehuhn.de/go/geom/matrix"
+	"seehuhn.de/go/geom/rect"
+	"seehuhn.de/go/pdf/graphics"
+)
+
+func TestSaveRestore(t *testing.T) {
+	r := NewRasterizer(rect.Rect{URx: 10, URy: 10})
+	r.CTM = matrix.Scale(2, 2)
+	r.Width = 3
+	r.Cap = graphics.LineCapRound
+	r.Dash = []float64{3, 1}
+	r.DashPhase = 0.5
+
+	var want GraphicsState
+	r.GetState(&want)
+
+	r.Save()
+	r.CTM = matrix.Identity
+	r.Clip = rect.Rect{URx: 5, URy: 5}
+	r.Flatness = 1
+	r.Width = 0
+	r.Cap = graphics.LineCapSquare
+	r.Join = graphics.LineJoinBevel
+	r.MiterLimit = 2
+	r.Dash[0] = 7 // modified in place
+	r.DashPhase = 0
+	r.StrokeAdjust = true
+	r.PushClipPath(rectPath(0, 0, 1, 1).Iter(), NonZero)
+
+	r.Save()
+	r.Dash = nil
+	r.PushClipPath(rectPath(0, 0, 1, 1).Iter(), NonZero)
+	if d := r.SaveDepth(); d != 2 {
+		t.Errorf("save depth %d, want 2", d)
+	}
+
+	r.Restore()
+	if r.Dash == nil || r.Dash[0] != 7 {
+		t.Errorf("inner restore: dash %v, want [7 1]", r.Dash)
+	}
+	if d := r.ClipDepth(); d != 1 {
+		t.Errorf("inner restore: clip depth %d, want 1", d)
+	}
+
+	r.Restore()
+	var got GraphicsState
+	r.GetState(&got)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("restored state %v, want %v", got, want)
+	}
+	if d := r.ClipDepth(); d != 0 {
+		t.Errorf("clip depth %d, want 0", d)
+	}
+
+	// Restore with an empty stack does nothing.
+	r.Restore()
+	if r.SaveDepth() != 0 || r.Width != 3 {
+		t.Error("Restore with empty stack changed the state")
+	}
+}
+
+func TestSaveRestoreClip(t *testing.T) {
+	r := NewRasterizer(rect.Rect{URx: 10, URy: 10})
+	r.Save()
+	r.PushClipPath(rectPath(2, 2, 4, 4).Iter(), NonZero)
+	r.Restore()
+
+	buf := renderCoverage(r, rectPath(0, 0, 10, 10), 10, 10)
+	for i, c := range buf {
+		if c != 1 {
+			t.Errorf("pixel (%d,%d) = %g, want 1", i%10, i/10, c)
+		}
+	}
+}
+
+func TestSaveRestoreAllocs(t *testing.T) {
+	r := NewRasterizer(rect.Rect{URx: 10, URy: 10})
+	r.Dash = []float64{1, 2, 3}
+	run := func() {
+		r.Save()
+		r.Dash[1] = 5
+		r.Save()
+		r.Dash = nil
+		r.Restore()
+		r.Restore()
+	}
+	run() // grow the buffers
+
+	if n := testing.AllocsPerRun(100, run); n != 0 {
+		t.Errorf("%g allocations per Save/Restore cycle, want 0", n)
+	}
+}
+
+func TestSaveRestorePopClip(t *testing.T) {
+	r := NewRasterizer(rect.Rect{URx: 10, URy: 10})
+	r.PushClipPath(rectPath(2, 2, 4, 4).Iter(), NonZero)
+	r.Save()
+
+	// Clip paths pushed after Save can be removed.
+	r.PushClipPath(rectPath(3, 3, 4, 4).Iter(), NonZero)
+	r.PopClip()
+	if d := r.ClipDepth(); d != 1 {
+		t.Fatalf("clip depth %d, want 1", d)
+	}
+
+	// The saved clip path cannot be removed, since a later PushClipPath
+	// would overwrite it before Restore.
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("PopClip below the saved depth did not panic")
+			}
+		}()
+		r.PopClip()
+	}()
+
+	r.PushClipPath(rectPath(0, 0, 10, 10).Iter(), NonZero)
+	r.Restore()
+	buf := renderCoverage(r, rectPath(0, 0, 10, 10), 10, 10)
+	for i, c := range buf {
+		x, y := i%10, i/10
+		var want float32
+		if x >= 2 && x < 4 && y >= 2 && y < 4 {
+			want = 1
+		}
+		if c != want {
+			t.Errorf("pixel (%d,%d) = %g, want %g", x, y, c, want)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package raster
+
+import (
+	"seehuhn.de/go/geom/path"
+	"seehuhn.de/go/geom/vec"
+)
+
+// ContainsFill reports whether the point pt, given in user space, lies
+// inside p when filled with the given rule. The path is transformed by
+// CTM and curves are flattened using Flatness, exactly as for filling.
+// Clip and clip paths are not taken into account.
+func (r *Rasterizer) ContainsFill(p path.Path, rule FillRule, pt vec.Vec2) bool {
+	return r.ContainsFillDevice(p, rule, r.toDevice(pt))
+}
+
+// ContainsFillDevice is like ContainsFill, but pt is given in device
+// space.
+func (r *Rasterizer) ContainsFillDevice(p path.Path, rule FillRule, pt vec.Vec2) bool {
+	r.collectPathEdges(p, false)
+	return windingInside(r.winding(pt), rule)
+}
+
+// ContainsStroke reports whether the point pt, given in user space, lies
+// within tolerance of the area painted by Stroke. The stroke outline is
+// computed from Width, Cap, Join, MiterLimit, Dash and DashPhase, as for
+// Stroke. The tolerance is measured in device pixels, so that a hit area
+// of a fixed size on screen is easy to specify; use zero for an exact
+// test. Clip and clip paths are not taken into account.
+func (r *Rasterizer) ContainsStroke(p path.Path, pt vec.Vec2, tolerance float64) bool {
+	return r.ContainsStrokeDevice(p, r.toDevice(pt), tolerance)
+}
+
+// ContainsStrokeDevice is like ContainsStroke, but pt is given in device
+// space.
+func (r *Rasterizer) ContainsStrokeDevice(p path.Path, pt vec.Vec2, tolerance float64) bool {
+	r.buildStrokeOutlines(p)
+	if len(r.strokeOffsets) == 0 {
+		return false
+	}
+
+	r.collectStrokeEdges(false)
+	if r.winding(pt) != 0 {
+		return true
+	}
+	if !(tolerance > 0) {
+		return false
+	}
+
+	// Outside the stroke, the distance to the painted area is the distance
+	// to the nearest outline edge. r.edges omits horizontal edges, so the
+	// polygons are used here.
+	tol2 := tolerance * tolerance
+	for i, start := range r.strokeOffsets {
+		end := len(r.stroke)
+		if i+1 < len(r.strokeOffsets) {
+			end = r.strokeOffsets[i+1]
+		}
+		poly := r.stroke[start:end]
+		if len(poly) < 2 {
+			continue
+		}
+
+		prev := r.toDevice(poly[len(poly)-1])
+		for _, v := range poly {
+			cur := r.toDevice(v)
+			if segmentDist2(pt, prev, cur) <= tol2 {
+				return true
+			}
+			prev = cur
+		}
+	}
+	return false
+}
+
+// toDevice transforms a point from user space to device space.
+func (r *Rasterizer) toDevice(v vec.Vec2) vec.Vec2 {
+	x, y := r.CTM.Apply(v.X, v.Y)
+	return vec.Vec2{X: x, Y: y}
+}
+
+// winding returns the winding number of the collected edges around the
+// device-space point pt. Edges crossing the horizontal line through pt to
+// the left of pt are counted with the same sign convention as in
+// accumulateEdge: +1 for downward edges and -1 for upward edges.
+func (r *Rasterizer) winding(pt vec.Vec2) int {
+	w := 0
+	for i := range r.edges {
+		e := &r.edges[i]
+		sign := 1
+		yTop, yBot := e.y0, e.y1
+		if yTop > yBot {
+			yTop, yBot = yBot, yTop
+			sign = -1
+		}
+		if pt.Y < yTop || pt.Y >= yBot {
+			continue
+		}
+		if e.x0+(pt.Y-e.y0)*e.dxdy < pt.X {
+			w += sign
+		}
+	}
+	return w
+}
+
+// windingInside applies the fill rule to a winding number.
+func windingInside(w int, rule FillRule) bool {
+	if rule == EvenOdd {
+		return w%2 != 0
+	}
+	return w != 0
+}
+
+// segmentDist2 returns the squared distance between pt and the line
+// segment from a to b.
+func segmentDist2(pt, a, b vec.Vec2) float64 {
+	ab := b.Sub(a)
+	ap := pt.Sub(a)
+	if l2 := ab.Dot(ab); l2 > 0 {
+		t := max(0, min(1, ap.Dot(ab)/l2))
+		ap = ap.Sub(ab.Mul(t))
+	}
+	return ap.Dot(ap)
+}
@@ -0,0 +1,112 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package raster
+
+import (
+	"testing"
+
+	"seehuhn.de/go/geom/matrix"
+	"seehuhn.de/go/geom/path"
+	"seehuhn.de/go/geom/rect"
+	"seehuhn.de/go/geom/vec"
+	"seehuhn.de/go/pdf/graphics"
+)
+
+func TestContainsFill(t *testing.T) {
+	// two nested squares with the same orientation
+	p := rectPath(0, 0, 10, 10)
+	p.Cmds = append(p.Cmds, rectPath(3, 3, 7, 7).Cmds...)
+	p.Coords = append(p.Coords, rectPath(3, 3, 7, 7).Coords...)
+
+	r := NewRasterizer(rect.Rect{URx: 5, URy: 5}) // Clip is ignored
+	cases := []struct {
+		pt      vec.Vec2
+		nonZero bool
+		evenOdd bool
+	}{
+		{vec.Vec2{X: 1, Y: 1}, true, true},
+		{vec.Vec2{X: 5, Y: 5}, true, false},
+		{vec.Vec2{X: 9.5, Y: 2}, true, true},
+		{vec.Vec2{X: 11, Y: 5}, false, false},
+		{vec.Vec2{X: -1, Y: 5}, false, false},
+	}
+	for _, c := range cases {
+		if got := r.ContainsFill(p.Iter(), NonZero, c.pt); got != c.nonZero {
+			t.Errorf("nonzero %v: got %t", c.pt, got)
+		}
+		if got := r.ContainsFill(p.Iter(), EvenOdd, c.pt); got != c.evenOdd {
+			t.Errorf("even-odd %v: got %t", c.pt, got)
+		}
+	}
+
+	// the path is transformed by the CTM
+	r.CTM = matrix.Translate(100, 0)
+	if !r.ContainsFill(p.Iter(), NonZero, vec.Vec2{X: 1, Y: 1}) {
+		t.Error("user space: point not found")
+	}
+	if !r.ContainsFillDevice(p.Iter(), NonZero, vec.Vec2{X: 101, Y: 1}) {
+		t.Error("device space: point not found")
+	}
+	if r.ContainsFillDevice(p.Iter(), NonZero, vec.Vec2{X: 1, Y: 1}) {
+		t.Error("device space: untransformed point found")
+	}
+}
+
+func TestContainsStroke(t *testing.T) {
+	p := (&path.Data{}).MoveTo(vec.Vec2{X: 10, Y: 10}).LineTo(vec.Vec2{X: 30, Y: 10})
+	r := NewRasterizer(rect.Rect{URx: 50, URy: 50})
+	r.Width = 4
+
+	cases := []struct {
+		pt   vec.Vec2
+		tol  float64
+		want bool
+	}{
+		{vec.Vec2{X: 20, Y: 11.5}, 0, true},
+		{vec.Vec2{X: 20, Y: 12.5}, 0, false},
+		{vec.Vec2{X: 20, Y: 12.5}, 1, true},
+		{vec.Vec2{X: 9, Y: 10}, 0, false}, // butt cap
+		{vec.Vec2{X: 9, Y: 10}, 1, true},
+		{vec.Vec2{X: 20, Y: 15}, 2, false},
+	}
+	for _, c := range cases {
+		if got := r.ContainsStroke(p.Iter(), c.pt, c.tol); got != c.want {
+			t.Errorf("%v, tolerance %g: got %t", c.pt, c.tol, got)
+		}
+	}
+
+	// caps and dashes are honoured
+	r.Cap = graphics.LineCapSquare
+	if !r.ContainsStroke(p.Iter(), vec.Vec2{X: 9, Y: 10}, 0) {
+		t.Error("square cap: point not found")
+	}
+	r.Dash = []float64{5, 5}
+	if r.ContainsStroke(p.Iter(), vec.Vec2{X: 18, Y: 10}, 0) {
+		t.Error("dash gap: point found")
+	}
+
+	// the tolerance is measured in device space
+	r.Cap = graphics.LineCapButt
+	r.Dash = nil
+	r.CTM = matrix.Scale(2, 2)
+	if !r.ContainsStrokeDevice(p.Iter(), vec.Vec2{X: 40, Y: 25}, 1) {
+		t.Error("device space: point not found")
+	}
+	if r.ContainsStroke(p.Iter(), vec.Vec2{X: 20, Y: 12.5}, 0.9) {
+		t.Error("device space tolerance: point found")
+	}
+}
@@ -0,0 +1,250 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package raster
+
+import (
+	"slices"
+
+	"seehuhn.de/go/geom/matrix"
+	"seehuhn.de/go/geom/path"
+	"seehuhn.de/go/geom/rect"
+)
+
+// SubpixelLayout describes the arrangement of the colour sub-pixels of an
+// LCD screen, for sub-pixel rendering.
+type SubpixelLayout int
+
+const (
+	// SubpixelRGB is for horizontal stripes in the order red, green, blue
+	// from left to right.
+	SubpixelRGB SubpixelLayout = iota
+
+	// SubpixelBGR is for horizontal stripes in the order blue, green, red
+	// from left to right.
+	SubpixelBGR
+
+	// SubpixelVRGB is for vertical stripes in the order red, green, blue
+	// from top (smaller y) to bottom.
+	SubpixelVRGB
+
+	// SubpixelVBGR is for vertical stripes in the order blue, green, red
+	// from top (smaller y) to bottom.
+	SubpixelVBGR
+)
+
+// vertical reports whether the sub-pixels are stacked vertically.
+func (l SubpixelLayout) vertical() bool {
+	return l == SubpixelVRGB || l == SubpixelVBGR
+}
+
+// LCDFilter is a 5-tap FIR filter, applied across sub-pixels to reduce
+// colour fringes. The taps should sum to 1. The zero value selects
+// DefaultLCDFilter.
+type LCDFilter [5]float32
+
+// DefaultLCDFilter is the default filter for sub-pixel rendering. The
+// weights are the ones used by FreeType's default LCD filter.
+var DefaultLCDFilter = LCDFilter{8.0 / 256, 77.0 / 256, 86.0 / 256, 77.0 / 256, 8.0 / 256}
+
+// FillNonZeroLCD fills the path using the nonzero winding rule, with
+// sub-pixel anti-aliasing for LCD screens. The emit callback receives
+// three coverage values per pixel, in the order red, green, blue, so that
+// rgb[3*i+c] is the coverage of channel c of pixel (xMin+i, y). The slice
+// argument is valid only during the call.
+//
+// The path is rasterized at three times the resolution in the direction
+// given by LCDLayout, and LCDFilter is applied to the sub-pixel coverage.
+func (r *Rasterizer) FillNonZeroLCD(p path.Path, emit func(y, xMin int, rgb []float32)) {
+	r.renderLCD(func(emit func(y, xMin int, coverage []float32)) {
+		r.fill(p, NonZero, emit)
+	}, emit)
+}
+
+// FillEvenOddLCD fills the path using the even-odd rule, with sub-pixel
+// anti-aliasing for LCD screens. The emit callback is as for
+// FillNonZeroLCD.
+func (r *Rasterizer) FillEvenOddLCD(p path.Path, emit func(y, xMin int, rgb []float32)) {
+	r.renderLCD(func(emit func(y, xMin int, coverage []float32)) {
+		r.fill(p, EvenOdd, emit)
+	}, emit)
+}
+
+// StrokeLCD strokes the path, with sub-pixel anti-aliasing for LCD
+// screens. The emit callback is as for FillNonZeroLCD.
+//
+// The stroke outline is built for whole device pixels, so that hairlines
+// are one pixel wide and StrokeAdjust snaps to pixel boundaries, as for
+// Stroke. Only the outline is rasterized at sub-pixel resolution.
+func (r *Rasterizer) StrokeLCD(p path.Path, emit func(y, xMin int, rgb []float32)) {
+	// Curves are flattened for the sub-pixel resolution.
+	flatness := r.Flatness
+	r.Flatness /= 3
+	r.buildStrokeOutlines(p)
+	r.Flatness = flatness
+
+	r.renderLCD(func(emit func(y, xMin int, coverage []float32)) {
+		r.fillStrokeOutlines(emit)
+	}, emit)
+}
+
+// renderLCD runs draw at three times the resolution along the sub-pixel
+// axis, filters the result and emits per-channel coverage.
+func (r *Rasterizer) renderLCD(draw func(emit func(y, xMin int, coverage []float32)), emit func(y, xMin int, rgb []float32)) {
+	xMin, xMax, yMin, yMax := r.clipBounds()
+	if xMin >= xMax || yMin >= yMax {
+		return
+	}
+	vertical := r.LCDLayout.vertical()
+
+	// Render the sub-pixels. The clip region is widened by two sub-pixels
+	// on each side, so that the filter sees all coverage which contributes
+	// to visible pixels. The clip path is applied after filtering.
+	savedCTM, savedClip, savedDepth := r.CTM, r.Clip, r.clipDepth
+	hi := rect.Rect{LLx: float64(xMin), LLy: float64(yMin), URx: float64(xMax), URy: float64(yMax)}
+	if vertical {
+		r.CTM = r.CTM.Mul(matrix.Scale(1, 3))
+		hi.LLy, hi.URy = 3*hi.LLy-2, 3*hi.URy+2
+	} else {
+		r.CTM = r.CTM.Mul(matrix.Scale(3, 1))
+		hi.LLx, hi.URx = 3*hi.LLx-2, 3*hi.URx+2
+	}
+	r.Clip = hi
+	r.clipDepth = 0
+
+	r.lcdRows = r.lcdRows[:0]
+	r.lcdCoverage = r.lcdCoverage[:0]
+	draw(func(y, xMin int, coverage []float32) {
+		if !r.bufferFits(4 * (len(r.lcdCoverage) + len(coverage))) {
+			r.abort("MaxBufferBytes", r.limits.MaxBufferBytes)
+			return
+		}
+		start := len(r.lcdCoverage)
+		r.lcdCoverage = append(r.lcdCoverage, coverage...)
+		r.lcdRows = append(r.lcdRows, storedRow{y: y, xMin: xMin, start: start, end: len(r.lcdCoverage)})
+	})
+
+	r.CTM, r.Clip, r.clipDepth = savedCTM, savedClip, savedDepth
+	if len(r.lcdRows) == 0 || r.aborted() {
+		return
+	}
+
+	// Copy the sub-pixel rows into a dense buffer.
+	sxMin, sxMax := r.lcdRows[0].xMin, r.lcdRows[0].xMin
+	syMin, syMax := r.lcdRows[0].y, r.lcdRows[0].y
+	for _, row := range r.lcdRows {
+		sxMin = min(sxMin, row.xMin)
+		sxMax = max(sxMax, row.xMin+row.end-row.start)
+		syMin = min(syMin, row.y)
+		syMax = max(syMax, row.y+1)
+	}
+	w, h := sxMax-sxMin, syMax-syMin
+	if !r.bufferFits(4 * w * h) {
+		r.abort("MaxBufferBytes", r.limits.MaxBufferBytes)
+		return
+	}
+	r.lcdDense = slices.Grow(r.lcdDense[:0], w*h)[:w*h]
+	buf := r.lcdDense
+	clear(buf)
+	for _, row := range r.lcdRows {
+		copy(buf[(row.y-syMin)*w+row.xMin-sxMin:], r.lcdCoverage[row.start:row.end])
+	}
+
+	// Determine the output pixels. The filter spreads the coverage of each
+	// sub-pixel over its two neighbours on either side.
+	pxMin, pxMax, pyMin, pyMax := sxMin, sxMax, syMin, syMax
+	if vertical {
+		pyMin, pyMax = floorDiv(syMin-2, 3), floorDiv(syMax+1, 3)+1
+	} else {
+		pxMin, pxMax = floorDiv(sxMin-2, 3), floorDiv(sxMax+1, 3)+1
+	}
+	pxMin, pxMax = max(pxMin, xMin), min(pxMax, xMax)
+	pyMin, pyMax = max(pyMin, yMin), min(pyMax, yMax)
+	if pxMin >= pxMax || pyMin >= pyMax {
+		return
+	}
+
+	filter := r.LCDFilter
+	if filter == (LCDFilter{}) {
+		filter = DefaultLCDFilter
+	}
+	var order [3]int // sub-pixel index of the red, green and blue channel
+	switch r.LCDLayout {
+	case SubpixelBGR, SubpixelVBGR:
+		order = [3]int{2, 1, 0}
+	default:
+		order = [3]int{0, 1, 2}
+	}
+
+	n := pxMax - pxMin
+	r.cover = slices.Grow(r.cover[:0], 3*n)[:3*n]
+	rgb := r.cover
+	for y := pyMin; y < pyMax; y++ {
+		for i := range n {
+			x := pxMin + i
+			for c, k := range order {
+				var sum float32
+				for j, f := range filter {
+					sx, sy := x, y
+					if vertical {
+						sy = 3*y + k + j - 2
+					} else {
+						sx = 3*x + k + j - 2
+					}
+					if sx >= sxMin && sx < sxMax && sy >= syMin && sy < syMax {
+						sum += f * buf[(sy-syMin)*w+sx-sxMin]
+					}
+				}
+				rgb[3*i+c] = min(sum, 1)
+			}
+		}
+		r.emitRowLCD(y, pxMin, rgb, emit)
+	}
+}
+
+// emitRowLCD applies the active clip path to a row of per-channel coverage
+// values and passes the non-zero portion to emit. The coverage slice is
+// modified in place.
+func (r *Rasterizer) emitRowLCD(y, xMin int, rgb []float32, emit func(y, xMin int, rgb []float32)) {
+	if mask := r.maskRow(y, xMin, len(rgb)/3); mask != nil {
+		for i, m := range mask {
+			rgb[3*i] *= m
+			rgb[3*i+1] *= m
+			rgb[3*i+2] *= m
+		}
+	}
+
+	lo, hi := 0, len(rgb)/3
+	for lo < hi && rgb[3*lo] == 0 && rgb[3*lo+1] == 0 && rgb[3*lo+2] == 0 {
+		lo++
+	}
+	for hi > lo && rgb[3*hi-3] == 0 && rgb[3*hi-2] == 0 && rgb[3*hi-1] == 0 {
+		hi--
+	}
+	if lo == hi {
+		return
+	}
+	emit(y, xMin+lo, rgb[3*lo:3*hi])
+}
+
+// floorDiv returns a/b rounded towards negative infinity, for b > 0.
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && a < 0 {
+		q--
+	}
+	return q
+}
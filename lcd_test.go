@@ -0,0 +1,193 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package raster
+
+import (
+	"math"
+	"testing"
+
+	"seehuhn.de/go/geom/path"
+	"seehuhn.de/go/geom/rect"
+	"seehuhn.de/go/geom/vec"
+)
+
+// renderLCD fills p with the nonzero rule in LCD mode and returns the
+// coverage as a w×h×3 buffer.
+func renderLCD(r *Rasterizer, p *path.Data, w, h int) []float32 {
+	buf := make([]float32, 3*w*h)
+	r.FillNonZeroLCD(p.Iter(), func(y, xMin int, rgb []float32) {
+		copy(buf[3*(y*w+xMin):], rgb)
+	})
+	return buf
+}
+
+func closeTo(a, b float32) bool {
+	return math.Abs(float64(a-b)) < 1e-5
+}
+
+func TestLCDEdge(t *testing.T) {
+	// A vertical edge at x=5: the filter spreads the sub-pixel coverage
+	// over neighbouring channels.
+	inner := float32(8+77+86) / 256
+	green := float32(256-8) / 256
+	outer := float32(8+77) / 256
+
+	cases := []struct {
+		layout SubpixelLayout
+		p      *path.Data
+		at     func(buf []float32, i, c int) float32
+		want   [2][3]float32 // pixel 4 and pixel 5
+	}{
+		{SubpixelRGB, rectPath(0, 0, 5, 10), pixelX, [2][3]float32{{1, green, inner}, {outer, 8.0 / 256, 0}}},
+		{SubpixelBGR, rectPath(0, 0, 5, 10), pixelX, [2][3]float32{{inner, green, 1}, {0, 8.0 / 256, outer}}},
+		{SubpixelVRGB, rectPath(0, 0, 10, 5), pixelY, [2][3]float32{{1, green, inner}, {outer, 8.0 / 256, 0}}},
+		{SubpixelVBGR, rectPath(0, 0, 10, 5), pixelY, [2][3]float32{{inner, green, 1}, {0, 8.0 / 256, outer}}},
+	}
+	for _, tc := range cases {
+		r := NewRasterizer(rect.Rect{URx: 10, URy: 10})
+		r.LCDLayout = tc.layout
+		buf := renderLCD(r, tc.p, 10, 10)
+		for k, pix := range []int{4, 5} {
+			for c := range 3 {
+				got := tc.at(buf, pix, c)
+				if !closeTo(got, tc.want[k][c]) {
+					t.Errorf("layout %d: pixel %d channel %d = %g, want %g",
+						tc.layout, pix, c, got, tc.want[k][c])
+				}
+			}
+		}
+	}
+}
+
+// pixelX returns channel c of pixel (i, 2) of a 10×10 LCD buffer.
+func pixelX(buf []float32, i, c int) float32 {
+	return buf[3*(2*10+i)+c]
+}
+
+// pixelY returns channel c of pixel (2, i) of a 10×10 LCD buffer.
+func pixelY(buf []float32, i, c int) float32 {
+	return buf[3*(i*10+2)+c]
+}
+
+func TestLCDUnfiltered(t *testing.T) {
+	// Without filtering, the channels are the coverage of the sub-pixels,
+	// and their mean is the ordinary coverage.
+	p := (&path.Data{}).
+		MoveTo(vec.Vec2{X: 1.2, Y: 0.7}).
+		LineTo(vec.Vec2{X: 8.9, Y: 2.3}).
+		LineTo(vec.Vec2{X: 4.1, Y: 9.6}).
+		Close()
+
+	for _, layout := range []SubpixelLayout{SubpixelRGB, SubpixelVBGR} {
+		r := NewRasterizer(rect.Rect{URx: 10, URy: 10})
+		r.LCDLayout = layout
+		r.LCDFilter = LCDFilter{0, 0, 1, 0, 0}
+		lcd := renderLCD(r, p, 10, 10)
+		gray := renderCoverage(r, p, 10, 10)
+
+		for i, g := range gray {
+			mean := (lcd[3*i] + lcd[3*i+1] + lcd[3*i+2]) / 3
+			if math.Abs(float64(mean-g)) > 1e-4 {
+				t.Errorf("layout %d: pixel (%d,%d): mean %g, want %g",
+					layout, i%10, i/10, mean, g)
+			}
+		}
+	}
+
+	// an edge one third into pixel 4 covers exactly the red sub-pixel
+	r := NewRasterizer(rect.Rect{URx: 10, URy: 10})
+	r.LCDFilter = LCDFilter{0, 0, 1, 0, 0}
+	buf := renderLCD(r, rectPath(0, 0, 4+1.0/3, 10), 10, 10)
+	got := [3]float32{pixelX(buf, 4, 0), pixelX(buf, 4, 1), pixelX(buf, 4, 2)}
+	if !closeTo(got[0], 1) || got[1] != 0 || got[2] != 0 {
+		t.Errorf("red sub-pixel: got %v", got)
+	}
+}
+
+func TestLCDClip(t *testing.T) {
+	r := NewRasterizer(rect.Rect{URx: 10, URy: 10})
+	r.PushClipPath(rectPath(3, 0, 10, 10).Iter(), NonZero)
+	r.Clip = rect.Rect{URx: 6, URy: 10}
+	buf := renderLCD(r, rectPath(0, 0, 10, 10), 10, 10)
+
+	for x := range 10 {
+		var want float32
+		if x >= 3 && x < 6 {
+			want = 1
+		}
+		for c := range 3 {
+			if got := pixelX(buf, x, c); !closeTo(got, want) {
+				t.Errorf("pixel %d channel %d = %g, want %g", x, c, got, want)
+			}
+		}
+	}
+}
+
+func TestStrokeLCD(t *testing.T) {
+	r := NewRasterizer(rect.Rect{URx: 10, URy: 10})
+	r.Width = 2
+	p := (&path.Data{}).MoveTo(vec.Vec2{X: 5, Y: 0}).LineTo(vec.Vec2{X: 5, Y: 10})
+
+	var total float32
+	r.StrokeLCD(p.Iter(), func(y, xMin int, rgb []float32) {
+		for _, v := range rgb {
+			total += v
+		}
+	})
+	// 2×10 pixels, three channels each
+	if !closeTo(total/3, 20) {
+		t.Errorf("total coverage %g, want 20", total/3)
+	}
+}
+
+func TestStrokeLCDDevicePixels(t *testing.T) {
+	// Hairlines and adjusted strokes refer to whole device pixels, not to
+	// sub-pixels.
+	p := (&path.Data{}).MoveTo(vec.Vec2{X: 5.3, Y: 0}).LineTo(vec.Vec2{X: 5.3, Y: 10})
+
+	r := NewRasterizer(rect.Rect{URx: 10, URy: 10})
+	r.Width = 0
+	var total float32
+	r.StrokeLCD(p.Iter(), func(y, xMin int, rgb []float32) {
+		for _, v := range rgb {
+			total += v
+		}
+	})
+	if !closeTo(total/3, 10) {
+		t.Errorf("hairline: total coverage %g, want 10", total/3)
+	}
+
+	r = NewRasterizer(rect.Rect{URx: 10, URy: 10})
+	r.Width = 1
+	r.StrokeAdjust = true
+	r.LCDFilter = LCDFilter{0, 0, 1, 0, 0}
+	buf := make([]float32, 3*10*10)
+	r.StrokeLCD(p.Iter(), func(y, xMin int, rgb []float32) {
+		copy(buf[3*(10*y+xMin):], rgb)
+	})
+	for x := range 10 {
+		want := float32(0)
+		if x == 5 {
+			want = 1
+		}
+		for c := range 3 {
+			if got := buf[3*(10*4+x)+c]; !closeTo(got, want) {
+				t.Errorf("adjusted: pixel %d channel %d: got %g, want %g", x, c, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,234 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package raster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"seehuhn.de/go/geom/path"
+)
+
+// Limits bounds the resources used by the context-aware rendering methods
+// FillContext, StrokeContext, FillStrokeContext and PushClipPathContext.
+// Fields which are zero or negative select the value from DefaultLimits.
+type Limits struct {
+	// MaxEdges is the maximum number of device-space edges of a single
+	// fill, stroke outline or clip path. For strokes, this includes the
+	// vertices generated for round joins and caps.
+	MaxEdges int
+
+	// MaxSegments is the maximum number of line segments in a single
+	// path, after flattening curves.
+	MaxSegments int
+
+	// MaxDashes is the maximum number of dashes along a single stroked
+	// path. Paths which would have more dashes are stroked as solid
+	// lines, as PDF viewers traditionally do for absurd dash patterns.
+	MaxDashes int
+
+	// MaxBufferBytes is the maximum size of the coverage buffers for a
+	// single path, including the sparse cell list and the rows stored by
+	// parallel workers. Paths whose buffers would be too large are
+	// rendered scanline by scanline on the calling goroutine instead; if
+	// a single scanline, the cell list or a clip mask exceeds the limit,
+	// rendering fails.
+	MaxBufferBytes int
+}
+
+// DefaultLimits gives the limits used for fields of Limits which are not
+// set.
+var DefaultLimits = Limits{
+	MaxEdges:       1 << 22,
+	MaxSegments:    maxPathSegments,
+	MaxDashes:      1 << 20,
+	MaxBufferBytes: 1 << 28,
+}
+
+// withDefaults returns a copy of l, where unset fields are replaced by
+// the values from DefaultLimits.
+func (l Limits) withDefaults() Limits {
+	if l.MaxEdges <= 0 {
+		l.MaxEdges = DefaultLimits.MaxEdges
+	}
+	if l.MaxSegments <= 0 {
+		l.MaxSegments = DefaultLimits.MaxSegments
+	}
+	if l.MaxDashes <= 0 {
+		l.MaxDashes = DefaultLimits.MaxDashes
+	}
+	if l.MaxBufferBytes <= 0 {
+		l.MaxBufferBytes = DefaultLimits.MaxBufferBytes
+	}
+	return l
+}
+
+// LimitError reports that rendering a path was aborted because one of the
+// Limits was exceeded.
+type LimitError struct {
+	// Limit is the name of the field of Limits, e.g. "MaxEdges".
+	Limit string
+
+	// Max is the value of the limit.
+	Max int
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("raster: %s limit of %d exceeded", e.Limit, e.Max)
+}
+
+// FillContext is like FillNonZero or FillEvenOdd, depending on rule, but
+// can be cancelled through ctx and is subject to r.Limits. The fields of
+// the Rasterizer and the path are checked as for TryFillNonZero.
+//
+// If the context is cancelled, ctx.Err() is returned. If a limit is
+// exceeded, a *LimitError is returned. In both cases some rows may
+// already have been emitted.
+func (r *Rasterizer) FillContext(ctx context.Context, p path.Path, rule FillRule, emit func(y, xMin int, coverage []float32)) error {
+	if err := r.validateFill(); err != nil {
+		return err
+	}
+	return r.runLimited(ctx, p, false, func() {
+		r.fill(p, rule, emit)
+	})
+}
+
+// StrokeContext is like Stroke, but can be cancelled through ctx and is
+// subject to r.Limits, as described for FillContext. Paths with more than
+// Limits.MaxDashes dashes are stroked without the dash pattern.
+func (r *Rasterizer) StrokeContext(ctx context.Context, p path.Path, emit func(y, xMin int, coverage []float32)) error {
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	return r.runLimited(ctx, p, true, func() {
+		r.Stroke(p, emit)
+	})
+}
+
+// FillStrokeContext is like FillStroke, but can be cancelled through ctx
+// and is subject to r.Limits, as described for FillContext and
+// StrokeContext.
+func (r *Rasterizer) FillStrokeContext(ctx context.Context, p path.Path, rule FillRule, emitFill, emitStroke func(y, xMin int, coverage []float32)) error {
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	return r.runLimited(ctx, p, true, func() {
+		r.FillStroke(p, rule, emitFill, emitStroke)
+	})
+}
+
+// PushClipPathContext is like PushClipPath, but can be cancelled through
+// ctx and is subject to r.Limits, as described for FillContext. If an
+// error is returned, an empty clip path has been pushed, which clips
+// everything; the call must still be matched by PopClip.
+func (r *Rasterizer) PushClipPathContext(ctx context.Context, p path.Path, rule FillRule) error {
+	depth := r.clipDepth
+	err := r.validateFill()
+	if err == nil {
+		err = r.runLimited(ctx, p, false, func() {
+			r.PushClipPath(p, rule)
+		})
+	}
+	if err != nil && r.clipDepth == depth {
+		r.pushEmptyClip()
+	}
+	return err
+}
+
+// runLimited checks p and then calls draw, with cancellation and the
+// limits enabled. The stroke argument tells whether draw strokes p. Dashes
+// are not counted here, since Limits.MaxDashes is enforced while stroking.
+func (r *Rasterizer) runLimited(ctx context.Context, p path.Path, stroke bool, draw func()) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	limits := r.Limits.withDefaults()
+	err := r.checkPath(p, stroke, false, float64(limits.MaxSegments))
+	var pathErr *PathError
+	if errors.As(err, &pathErr) && pathErr.Err == ErrTooComplex {
+		return &LimitError{Limit: "MaxSegments", Max: limits.MaxSegments}
+	} else if err != nil {
+		return err
+	}
+
+	r.limited = true
+	r.limits = limits
+	r.done = ctx.Done()
+	r.abortErr = nil
+	defer func() {
+		// also reset if draw panics, e.g. in the emit callback
+		r.limited = false
+		r.done = nil
+		r.abortErr = nil
+	}()
+	draw()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return r.abortErr
+}
+
+// abort stops the current operation because the given limit has been
+// exceeded. Only the first call has an effect.
+func (r *Rasterizer) abort(limit string, value int) {
+	if r.abortErr == nil {
+		r.abortErr = &LimitError{Limit: limit, Max: value}
+	}
+}
+
+// aborted reports whether the current operation should stop, because a
+// limit was exceeded or because the context was cancelled. The check for
+// cancellation is safe for concurrent use.
+func (r *Rasterizer) aborted() bool {
+	return r.abortErr != nil || r.canceled()
+}
+
+// canceled reports whether the context of the current operation has been
+// cancelled. It is safe for concurrent use.
+func (r *Rasterizer) canceled() bool {
+	if r.done == nil {
+		return false
+	}
+	select {
+	case <-r.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// bufferFits reports whether a buffer of the given size in bytes is
+// allowed by the limits.
+func (r *Rasterizer) bufferFits(bytes int) bool {
+	return !r.limited || bytes <= r.limits.MaxBufferBytes
+}
+
+// tooManyDashes reports whether dashing the flattened path would exceed
+// Limits.MaxDashes.
+func (r *Rasterizer) tooManyDashes() bool {
+	if !r.limited {
+		return false
+	}
+	length := 0.0
+	for i := range r.segs {
+		length += r.segs[i].B.Sub(r.segs[i].A).Length()
+	}
+	return !(r.dashSegments(length) <= float64(r.limits.MaxDashes))
+}
@@ -0,0 +1,323 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package raster
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"seehuhn.de/go/geom/path"
+	"seehuhn.de/go/geom/rect"
+	"seehuhn.de/go/geom/vec"
+	"seehuhn.de/go/pdf/graphics"
+)
+
+// limitOf returns the name of the limit reported by err, or "" if err is
+// not a *LimitError.
+func limitOf(err error) string {
+	var limitErr *LimitError
+	if errors.As(err, &limitErr) {
+		return limitErr.Limit
+	}
+	return ""
+}
+
+func TestContextCancel(t *testing.T) {
+	const size = 200
+
+	for _, threshold := range []int{1 << 30, 0} {
+		r := NewRasterizer(rect.Rect{URx: size, URy: size})
+		r.smallPathThreshold = threshold
+		p := rectPath(0, 0, size, size)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		rows := 0
+		err := r.FillContext(ctx, p.Iter(), NonZero, func(y, xMin int, coverage []float32) {
+			rows++
+			cancel()
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("threshold %d: got error %v, want %v", threshold, err, context.Canceled)
+		}
+		if rows != 1 {
+			t.Errorf("threshold %d: %d rows emitted after cancellation, want 1", threshold, rows)
+		}
+
+		// A cancelled context stops the operation before any work is done.
+		rows = 0
+		err = r.StrokeContext(ctx, p.Iter(), func(y, xMin int, coverage []float32) {
+			rows++
+		})
+		if !errors.Is(err, context.Canceled) || rows != 0 {
+			t.Errorf("threshold %d: got error %v and %d rows", threshold, err, rows)
+		}
+	}
+}
+
+func TestLimits(t *testing.T) {
+	ctx := context.Background()
+	emit := func(y, xMin int, coverage []float32) {}
+
+	r := NewRasterizer(rect.Rect{URx: 100, URy: 100})
+	r.Limits = Limits{MaxEdges: 50}
+	if err := r.FillContext(ctx, starPath(101, 100).Iter(), NonZero, emit); limitOf(err) != "MaxEdges" {
+		t.Errorf("many edges: got error %v", err)
+	}
+	if err := r.FillContext(ctx, starPath(11, 100).Iter(), NonZero, emit); err != nil {
+		t.Errorf("few edges: got error %v", err)
+	}
+
+	// A cubic spanning 1e8 device units.
+	huge := (&path.Data{}).
+		MoveTo(vec.Vec2{X: 0, Y: 0}).
+		CubeTo(vec.Vec2{X: 1e8, Y: 0}, vec.Vec2{X: 0, Y: 1e8}, vec.Vec2{X: 10, Y: 10})
+	r.Limits = Limits{MaxSegments: 1000}
+	if err := r.FillContext(ctx, huge.Iter(), NonZero, emit); limitOf(err) != "MaxSegments" {
+		t.Errorf("huge curve: got error %v", err)
+	}
+
+	// Round joins of an enormous width need too many vertices.
+	r.Limits = Limits{MaxEdges: 1000}
+	r.Width = 1e6
+	r.Join = graphics.LineJoinRound
+	if err := r.StrokeContext(ctx, starPath(5, 100).Iter(), emit); limitOf(err) != "MaxEdges" {
+		t.Errorf("huge round joins: got error %v", err)
+	}
+	r.Width = 1
+	r.Join = graphics.LineJoinMiter
+
+	r.Limits = Limits{MaxBufferBytes: 100}
+	if err := r.FillContext(ctx, rectPath(0, 0, 50, 50).Iter(), NonZero, emit); limitOf(err) != "MaxBufferBytes" {
+		t.Errorf("small buffer: got error %v", err)
+	}
+	if err := r.PushClipPathContext(ctx, rectPath(0, 0, 50, 50).Iter(), NonZero); limitOf(err) != "MaxBufferBytes" {
+		t.Errorf("clip mask: got error %v", err)
+	}
+	if r.limited || r.abortErr != nil {
+		t.Error("limits still active after the operation")
+	}
+}
+
+func TestLimitsClipBalanced(t *testing.T) {
+	const size = 20
+	r := NewRasterizer(rect.Rect{URx: size, URy: size})
+	r.PushClipPath(rectPath(0, 0, 10, size).Iter(), NonZero)
+
+	// A failed push clips everything, and is undone by PopClip.
+	r.Limits = Limits{MaxBufferBytes: 100}
+	err := r.PushClipPathContext(context.Background(), rectPath(0, 0, 15, 15).Iter(), NonZero)
+	if limitOf(err) != "MaxBufferBytes" {
+		t.Errorf("got error %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := r.PushClipPathContext(ctx, rectPath(0, 0, 15, 15).Iter(), NonZero); err != context.Canceled {
+		t.Errorf("got error %v", err)
+	}
+	if d := r.ClipDepth(); d != 3 {
+		t.Fatalf("clip depth %d, want 3", d)
+	}
+	r.Limits = Limits{}
+	for _, c := range renderCoverage(r, rectPath(0, 0, size, size), size, size) {
+		if c != 0 {
+			t.Fatal("painting through an empty clip path")
+		}
+	}
+
+	r.PopClip()
+	r.PopClip()
+	got := renderCoverage(r, rectPath(0, 0, size, size), size, size)
+	for i, c := range got {
+		want := float32(0)
+		if i%size < 10 {
+			want = 1
+		}
+		if c != want {
+			t.Fatalf("pixel (%d,%d) = %g, want %g", i%size, i/size, c, want)
+		}
+	}
+}
+
+func TestLimitsBuffer(t *testing.T) {
+	const size = 100
+
+	// Without room for the 2D buffers, the scanline approach gives the
+	// same result.
+	r := NewRasterizer(rect.Rect{URx: size, URy: size})
+	p := starPath(7, size)
+	want := renderCoverage(r, p, size, size)
+
+	r.Limits = Limits{MaxBufferBytes: 8 * size}
+	got := make([]float32, size*size)
+	err := r.FillContext(context.Background(), p.Iter(), NonZero, func(y, xMin int, coverage []float32) {
+		copy(got[y*size+xMin:], coverage)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("pixel (%d,%d) = %g, want %g", i%size, i/size, got[i], want[i])
+		}
+	}
+}
+
+func TestLimitsBufferSparse(t *testing.T) {
+	const size = 1000
+	ctx := context.Background()
+
+	// Approach C would need about 50 kB for its cells; the scanline
+	// approach gives the same result.
+	p := rectPath(0.5, 0.5, size-0.5, size-0.5)
+	want := renderCoverage(NewRasterizer(rect.Rect{URx: size, URy: size}), p, size, size)
+
+	r := NewRasterizer(rect.Rect{URx: size, URy: size})
+	r.Limits = Limits{MaxBufferBytes: 10000}
+	got := make([]float32, size*size)
+	err := r.FillContext(ctx, p.Iter(), NonZero, func(y, xMin int, coverage []float32) {
+		copy(got[y*size+xMin:], coverage)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cap(r.cells) != 0 {
+		t.Error("sparse cells were allocated")
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("pixel (%d,%d) = %g, want %g", i%size, i/size, got[i], want[i])
+		}
+	}
+}
+
+func TestLimitsBufferBands(t *testing.T) {
+	const size = 200
+	ctx := context.Background()
+	star := starPath(23, size)
+
+	r := NewRasterizer(rect.Rect{URx: size, URy: size})
+	r.smallPathThreshold = 0
+	r.sparseCellRatio = 0
+	want := renderCoverage(r, star, size, size)
+
+	// The stored rows of the bands don't fit, so the path is rendered
+	// serially.
+	r.Workers = 4
+	r.Limits = Limits{MaxBufferBytes: 8 * size * 4}
+	got := make([]float32, size*size)
+	err := r.FillContext(ctx, star.Iter(), NonZero, func(y, xMin int, coverage []float32) {
+		copy(got[y*size+xMin:], coverage)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.bandOutputs) != 0 {
+		t.Error("rows were stored for parallel bands")
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("pixel (%d,%d) = %g, want %g", i%size, i/size, got[i], want[i])
+		}
+	}
+}
+
+func TestLimitsDash(t *testing.T) {
+	const size = 40
+
+	p := (&path.Data{}).MoveTo(vec.Vec2{X: 5, Y: 20}).LineTo(vec.Vec2{X: 35, Y: 20})
+	r := NewRasterizer(rect.Rect{URx: size, URy: size})
+	r.Width = 2
+	want := renderStroke(r, p, size, size)
+
+	// An absurd dash pattern is stroked solid.
+	r.Dash = []float64{1e-9}
+	got := make([]float32, size*size)
+	err := r.StrokeContext(context.Background(), p.Iter(), func(y, xMin int, coverage []float32) {
+		if len(r.Dash) != 1 {
+			t.Errorf("dash pattern changed to %v while stroking", r.Dash)
+		}
+		copy(got[y*size+xMin:], coverage)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("pixel (%d,%d) = %g, want %g", i%size, i/size, got[i], want[i])
+		}
+	}
+	if len(r.Dash) != 1 {
+		t.Errorf("dash pattern changed to %v", r.Dash)
+	}
+}
+
+func TestLimitsPanic(t *testing.T) {
+	r := NewRasterizer(rect.Rect{URx: 20, URy: 20})
+	r.Limits = Limits{MaxBufferBytes: 100}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("emit did not panic")
+			}
+		}()
+		r.FillContext(context.Background(), rectPath(0, 0, 5, 5).Iter(), NonZero, func(y, xMin int, coverage []float32) {
+			panic("emit")
+		})
+	}()
+	if r.limited || r.done != nil || r.abortErr != nil {
+		t.Error("limits still active after a panic in emit")
+	}
+
+	// The rasterizer is still usable, without limits.
+	rows := 0
+	r.FillNonZero(rectPath(0, 0, 15, 15).Iter(), func(y, xMin int, coverage []float32) {
+		rows++
+	})
+	if rows != 15 {
+		t.Errorf("got %d rows, want 15", rows)
+	}
+}
+
+func TestLimitsAllocs(t *testing.T) {
+	// The checks for cancellation must not make the results of the path
+	// iteration escape to the heap. Range-over-func loops over an opaque
+	// iterator cost a few allocations for the loop state; FillContext
+	// iterates over the path twice, once in checkPath.
+	const size = 200
+	star := starPath(23, size)
+	r := NewRasterizer(rect.Rect{URx: size, URy: size})
+	r.smallPathThreshold = 0
+	r.sparseCellRatio = 0
+	emit := func(y, xMin int, coverage []float32) {}
+	ctx := context.Background()
+
+	cases := []struct {
+		name      string
+		fill      func()
+		maxAllocs float64
+	}{
+		{"FillNonZero", func() { r.FillNonZero(star.Iter(), emit) }, 4},
+		{"FillContext", func() { r.FillContext(ctx, star.Iter(), NonZero, emit) }, 11},
+	}
+	for _, c := range cases {
+		c.fill()
+		if allocs := testing.AllocsPerRun(20, c.fill); allocs > c.maxAllocs {
+			t.Errorf("%s: %g allocations, want at most %g", c.name, allocs, c.maxAllocs)
+		}
+	}
+}
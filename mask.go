@@ -0,0 +1,239 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package raster
+
+import (
+	"cmp"
+	"image"
+	"image/color"
+	"slices"
+)
+
+// Mask stores coverage values for later use. Only the non-zero part of
+// each row is stored. A Mask implements image.Image, with the coverage
+// as a color.Alpha16 value, so that it can be used as the mask argument
+// of draw.DrawMask.
+//
+// To fill a Mask, pass its Emit method as the emit callback to
+// FillNonZero, FillEvenOdd or Stroke. Several shapes can be rendered into
+// the same mask. The zero value is an empty mask, ready to use.
+type Mask struct {
+	rows     []storedRow // sorted by y, at most one row per y
+	coverage []float32   // storage for rows
+	bounds   image.Rectangle
+
+	// If rows have been merged, the storage is no longer contiguous and in
+	// row order. unused counts the values which are no longer referenced.
+	unordered bool
+	unused    int
+	spare     []float32 // storage for compact
+}
+
+// Reset removes all coverage from the mask. The storage is kept for reuse.
+func (m *Mask) Reset() {
+	m.rows = m.rows[:0]
+	m.coverage = m.coverage[:0]
+	m.bounds = image.Rectangle{}
+	m.unordered = false
+	m.unused = 0
+}
+
+// Emit adds a row of coverage values to the mask. If the mask already has
+// coverage in row y, the two are combined as a + b − a·b, as when painting
+// both shapes into an alpha image. Rows are stored most efficiently when
+// added in order of increasing y, as done by a single call to one of the
+// Rasterizer drawing methods.
+func (m *Mask) Emit(y, xMin int, coverage []float32) {
+	coverage, offset := trimZeros(coverage)
+	if coverage == nil {
+		return
+	}
+	xMin += offset
+	m.bounds = m.bounds.Union(image.Rect(xMin, y, xMin+len(coverage), y+1))
+
+	n := len(m.rows)
+	if n == 0 || m.rows[n-1].y < y {
+		start := len(m.coverage)
+		m.coverage = append(m.coverage, coverage...)
+		m.rows = append(m.rows, storedRow{y: y, xMin: xMin, start: start, end: len(m.coverage)})
+		return
+	}
+	m.merge(y, xMin, coverage)
+}
+
+// merge combines a row of coverage values with the stored rows. The new
+// row is placed at the end of the storage.
+func (m *Mask) merge(y, xMin int, coverage []float32) {
+	m.unordered = true
+
+	i, found := slices.BinarySearchFunc(m.rows, y, func(row storedRow, y int) int {
+		return cmp.Compare(row.y, y)
+	})
+	if !found {
+		start := len(m.coverage)
+		m.coverage = append(m.coverage, coverage...)
+		m.rows = slices.Insert(m.rows, i, storedRow{y: y, xMin: xMin, start: start, end: len(m.coverage)})
+		return
+	}
+
+	old := m.rows[i]
+	oldLen := old.end - old.start
+	x0 := min(xMin, old.xMin)
+	x1 := max(xMin+len(coverage), old.xMin+oldLen)
+
+	start := len(m.coverage)
+	m.coverage = slices.Grow(m.coverage, x1-x0)[:start+x1-x0]
+	buf := m.coverage[start:]
+	clear(buf)
+	copy(buf[old.xMin-x0:], m.coverage[old.start:old.end])
+	for k, b := range coverage {
+		a := buf[xMin-x0+k]
+		buf[xMin-x0+k] = a + b - a*b
+	}
+	m.rows[i] = storedRow{y: y, xMin: x0, start: start, end: len(m.coverage)}
+
+	m.unused += oldLen
+	if m.unused > len(m.coverage)/2 {
+		m.compact()
+	}
+}
+
+// compact stores the rows contiguously and in order of increasing y.
+func (m *Mask) compact() {
+	buf := m.spare[:0]
+	for i, row := range m.rows {
+		start := len(buf)
+		buf = append(buf, m.coverage[row.start:row.end]...)
+		m.rows[i].start, m.rows[i].end = start, len(buf)
+	}
+	m.spare = m.coverage[:0]
+	m.coverage = buf
+	m.unordered = false
+	m.unused = 0
+}
+
+// ColorModel implements the image.Image interface.
+func (m *Mask) ColorModel() color.Model {
+	return color.Alpha16Model
+}
+
+// Bounds implements the image.Image interface. The result is the smallest
+// rectangle which contains all pixels with non-zero coverage.
+func (m *Mask) Bounds() image.Rectangle {
+	return m.bounds
+}
+
+// At implements the image.Image interface.
+func (m *Mask) At(x, y int) color.Color {
+	return color.Alpha16{A: uint16(m.Value(x, y)*0xffff + 0.5)}
+}
+
+// Value returns the coverage of pixel (x, y), in the range [0, 1].
+func (m *Mask) Value(x, y int) float32 {
+	row := m.row(y)
+	if row == nil || x < row.xMin || x >= row.xMin+row.end-row.start {
+		return 0
+	}
+	return m.coverage[row.start+x-row.xMin]
+}
+
+// row returns the stored row for y, or nil if there is none.
+func (m *Mask) row(y int) *storedRow {
+	i, found := slices.BinarySearchFunc(m.rows, y, func(row storedRow, y int) int {
+		return cmp.Compare(row.y, y)
+	})
+	if found {
+		return &m.rows[i]
+	}
+	return nil
+}
+
+// Replay passes the stored rows, translated by (dx, dy), to emit. This can
+// be used to paint the same shape repeatedly, for example using a
+// composite.Compositor. Rows are delivered in order of increasing y; the
+// slice argument of emit is valid only during the call.
+func (m *Mask) Replay(dx, dy int, emit func(y, xMin int, coverage []float32)) {
+	for _, row := range m.rows {
+		emit(row.y+dy, row.xMin+dx, m.coverage[row.start:row.end])
+	}
+}
+
+// Intersect multiplies the coverage of m by the coverage of other, so
+// that m afterwards contains the intersection of the two shapes. Other
+// may be m itself.
+func (m *Mask) Intersect(other *Mask) {
+	if m.sharesStorage(other) {
+		// The rows of m are overwritten below.
+		other = &Mask{rows: slices.Clone(other.rows), coverage: slices.Clone(other.coverage)}
+	}
+	if m.unordered {
+		m.compact()
+	}
+
+	pos := 0 // write position in m.coverage
+	n := 0   // number of rows kept
+	m.bounds = image.Rectangle{}
+	for _, row := range m.rows {
+		o := other.row(row.y)
+		if o == nil {
+			continue
+		}
+
+		// overlap of the two rows
+		xMin := max(row.xMin, o.xMin)
+		xMax := min(row.xMin+row.end-row.start, o.xMin+o.end-o.start)
+		if xMin >= xMax {
+			continue
+		}
+		a := m.coverage[row.start+xMin-row.xMin : row.start+xMax-row.xMin]
+		b := other.coverage[o.start+xMin-o.xMin : o.start+xMax-o.xMin]
+		for i := range a {
+			a[i] *= b[i]
+		}
+		a, offset := trimZeros(a)
+		if a == nil {
+			continue
+		}
+		xMin += offset
+
+		// The kept part of a row never starts after its old position,
+		// so the rows can be compacted in place.
+		copy(m.coverage[pos:], a)
+		m.rows[n] = storedRow{y: row.y, xMin: xMin, start: pos, end: pos + len(a)}
+		pos += len(a)
+		n++
+
+		m.bounds = m.bounds.Union(image.Rect(xMin, row.y, xMin+len(a), row.y+1))
+	}
+	m.rows = m.rows[:n]
+	m.coverage = m.coverage[:pos]
+}
+
+// sharesStorage reports whether m and other use the same storage, for
+// example because other is m or a copy of m.
+func (m *Mask) sharesStorage(other *Mask) bool {
+	return other == m || sameArray(m.rows, other.rows) || sameArray(m.coverage, other.coverage)
+}
+
+// sameArray reports whether a and b extend to the end of the same
+// backing array.
+func sameArray[T any](a, b []T) bool {
+	if cap(a) == 0 || cap(b) == 0 {
+		return false
+	}
+	return &a[:cap(a)][cap(a)-1] == &b[:cap(b)][cap(b)-1]
+}
@@ -0,0 +1,231 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package raster
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"sync"
+	"testing"
+
+	"seehuhn.de/go/geom/rect"
+)
+
+func TestMask(t *testing.T) {
+	const size = 50
+	star := starPath(9, size)
+	r := NewRasterizer(rect.Rect{URx: size, URy: size})
+	want := renderCoverage(r, star, size, size)
+
+	m := &Mask{}
+	r.FillNonZero(star.Iter(), m.Emit)
+
+	var bbox image.Rectangle
+	for y := range size {
+		for x := range size {
+			v := want[y*size+x]
+			if got := m.Value(x, y); got != v {
+				t.Errorf("pixel (%d,%d) = %g, want %g", x, y, got, v)
+			}
+			if v != 0 {
+				bbox = bbox.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	if m.Bounds() != bbox {
+		t.Errorf("bounds %v, want %v", m.Bounds(), bbox)
+	}
+	if m.Value(-1, 20) != 0 || m.Value(20, size+3) != 0 {
+		t.Error("non-zero coverage outside the mask")
+	}
+
+	m.Reset()
+	if !m.Bounds().Empty() || m.Value(size/2, size/2) != 0 {
+		t.Error("Reset did not clear the mask")
+	}
+}
+
+func TestMaskDrawMask(t *testing.T) {
+	r := NewRasterizer(rect.Rect{URx: 10, URy: 10})
+	m := &Mask{}
+	r.FillNonZero(rectPath(2, 2, 6.5, 8).Iter(), m.Emit)
+
+	dst := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	src := image.NewUniform(color.RGBA{R: 255, A: 255})
+	draw.DrawMask(dst, dst.Bounds(), src, image.Point{}, m, image.Point{}, draw.Over)
+
+	if c := dst.RGBAAt(3, 3); c != (color.RGBA{R: 255, A: 255}) {
+		t.Errorf("interior: got %v", c)
+	}
+	if c := dst.RGBAAt(6, 3); c.A < 127 || c.A > 128 {
+		t.Errorf("half-covered pixel: got %v", c)
+	}
+	if c := dst.RGBAAt(1, 3); c.A != 0 {
+		t.Errorf("exterior: got %v", c)
+	}
+}
+
+func TestMaskReplay(t *testing.T) {
+	r := NewRasterizer(rect.Rect{URx: 10, URy: 10})
+	m := &Mask{}
+	r.FillNonZero(rectPath(1, 1, 3, 2.5).Iter(), m.Emit)
+
+	shifted := &Mask{}
+	m.Replay(5, -1, shifted.Emit)
+	want := image.Rect(6, 0, 8, 2)
+	if shifted.Bounds() != want {
+		t.Errorf("bounds %v, want %v", shifted.Bounds(), want)
+	}
+	if v := shifted.Value(7, 1); v != 0.5 {
+		t.Errorf("pixel (7,1) = %g, want 0.5", v)
+	}
+}
+
+func TestMaskIntersect(t *testing.T) {
+	r := NewRasterizer(rect.Rect{URx: 10, URy: 10})
+	a := &Mask{}
+	r.FillNonZero(rectPath(0, 0, 6, 6).Iter(), a.Emit)
+	b := &Mask{}
+	r.FillNonZero(rectPath(3.5, 4, 10, 10).Iter(), b.Emit)
+
+	a.Intersect(b)
+	if want := image.Rect(3, 4, 6, 6); a.Bounds() != want {
+		t.Errorf("bounds %v, want %v", a.Bounds(), want)
+	}
+	for y := range 10 {
+		for x := range 10 {
+			var want float32
+			if y >= 4 && y < 6 && x >= 3 && x < 6 {
+				want = 1
+				if x == 3 {
+					want = 0.5
+				}
+			}
+			if got := a.Value(x, y); got != want {
+				t.Errorf("pixel (%d,%d) = %g, want %g", x, y, got, want)
+			}
+		}
+	}
+
+	// disjoint shapes give an empty mask
+	c := &Mask{}
+	r.FillNonZero(rectPath(8, 8, 9, 9).Iter(), c.Emit)
+	a.Intersect(c)
+	if !a.Bounds().Empty() {
+		t.Errorf("bounds %v, want empty", a.Bounds())
+	}
+}
+
+func TestMaskMerge(t *testing.T) {
+	const size = 40
+	r := NewRasterizer(rect.Rect{URx: size, URy: size})
+	r.Width = 3
+	square := rectPath(5, 5, 25.5, 25.5)
+	star := starPath(7, size)
+	fill := renderCoverage(r, square, size, size)
+	stroke := renderStroke(r, star, size, size)
+
+	// fill and stroke into one mask
+	m := &Mask{}
+	r.FillNonZero(square.Iter(), m.Emit)
+	r.Stroke(star.Iter(), m.Emit)
+	for y := range size {
+		for x := range size {
+			a, b := fill[y*size+x], stroke[y*size+x]
+			want := a + b - a*b
+			if got := m.Value(x, y); !closeTo(got, want) {
+				t.Errorf("pixel (%d,%d) = %g, want %g", x, y, got, want)
+			}
+		}
+	}
+
+	// Intersect compacts the merged rows
+	full := &Mask{}
+	r.FillNonZero(rectPath(0, 0, size, size).Iter(), full.Emit)
+	m2 := &Mask{}
+	r.FillNonZero(square.Iter(), m2.Emit)
+	r.Stroke(star.Iter(), m2.Emit)
+	m2.Intersect(full)
+	for y := range size {
+		for x := range size {
+			if got, want := m2.Value(x, y), m.Value(x, y); got != want {
+				t.Errorf("intersected pixel (%d,%d) = %g, want %g", x, y, got, want)
+			}
+		}
+	}
+}
+
+func TestMaskFillBands(t *testing.T) {
+	const size = 200
+	star := starPath(23, size)
+	r := NewRasterizer(rect.Rect{URx: size, URy: size})
+	r.smallPathThreshold = 0
+	r.sparseCellRatio = 0
+	want := renderCoverage(r, star, size, size)
+
+	r.Workers = 4
+	m := &Mask{}
+	var mu sync.Mutex
+	r.FillBands(star.Iter(), NonZero, func(band, y, xMin int, coverage []float32) {
+		mu.Lock()
+		m.Emit(y, xMin, coverage)
+		mu.Unlock()
+	})
+	for y := range size {
+		for x := range size {
+			if got := m.Value(x, y); got != want[y*size+x] {
+				t.Errorf("pixel (%d,%d) = %g, want %g", x, y, got, want[y*size+x])
+			}
+		}
+	}
+}
+
+func TestMaskIntersectSelf(t *testing.T) {
+	r := NewRasterizer(rect.Rect{URx: 10, URy: 10})
+	fill := func(m *Mask) {
+		r.FillNonZero(starPath(7, 10).Iter(), m.Emit)
+		r.FillNonZero(rectPath(0.5, 2.5, 4.5, 3.5).Iter(), m.Emit) // merged rows
+	}
+
+	want := &Mask{}
+	fill(want)
+	other := &Mask{}
+	fill(other)
+	want.Intersect(other)
+
+	a := &Mask{}
+	fill(a)
+	a.Intersect(a)
+	b := &Mask{}
+	fill(b)
+	c := *b // shares the storage of b
+	b.Intersect(&c)
+
+	for _, m := range []*Mask{a, b} {
+		if m.Bounds() != want.Bounds() {
+			t.Errorf("bounds %v, want %v", m.Bounds(), want.Bounds())
+		}
+		for y := range 10 {
+			for x := range 10 {
+				if got, want := m.Value(x, y), want.Value(x, y); got != want {
+					t.Errorf("pixel (%d,%d) = %g, want %g", x, y, got, want)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,215 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package raster
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"seehuhn.de/go/geom/path"
+)
+
+const (
+	// bandsPerWorker is the number of bands per worker goroutine. Using
+	// more bands than workers balances the load when the path is not
+	// spread evenly over the clip region.
+	bandsPerWorker = 4
+
+	// minBandHeight is the minimum number of scanlines per band.
+	minBandHeight = 16
+)
+
+// bandWorker holds the per-goroutine state for parallel band rendering.
+// Workers are kept on the Rasterizer and reused by later calls.
+type bandWorker struct {
+	r      *Rasterizer
+	band   int // band currently rendered
+	cover  []float32
+	area   []float32
+	active []int
+
+	// Method values, bound once so that they do not allocate per call.
+	run  func()
+	emit func(y, xMin int, coverage []float32)
+}
+
+// bandOutput collects the rows of one band, until they can be passed to
+// the emit callback in order.
+type bandOutput struct {
+	rows     []storedRow
+	coverage []float32
+	ready    bool // all rows of the band have been stored
+}
+
+// bandJob describes the bands currently being rendered by the workers.
+type bandJob struct {
+	xMin, xMax, yMin, yMax int
+	rule                   FillRule
+	n                      int // number of bands
+
+	next atomic.Int32   // next band to be rendered
+	wg   sync.WaitGroup // running workers
+	done chan int       // finished bands, for in-order delivery
+}
+
+// FillBands fills the path using the given fill rule, like FillNonZero or
+// FillEvenOdd, but passes rows to emit directly from the worker goroutines
+// instead of collecting them for in-order delivery.
+//
+// If Workers is at least 2, the rows of a large path rendered with the
+// active edge list are split into horizontal bands, numbered from 0 in
+// order of increasing y. Different
+// bands are delivered concurrently, so emit must be safe for concurrent
+// use. The rows of a single band are delivered by one goroutine, in order
+// of increasing y. Otherwise, including for small paths and for paths
+// where only few pixels lie on edges (see Workers), all rows are passed
+// to emit as band 0, on the calling goroutine.
+//
+// The coverage values are bit-identical to the ones produced by
+// FillNonZero or FillEvenOdd.
+func (r *Rasterizer) FillBands(p path.Path, rule FillRule, emit func(band, y, xMin int, coverage []float32)) {
+	r.bandEmit = emit
+	defer func() { r.bandEmit = nil }()
+
+	r.fill(p, rule, func(y, xMin int, coverage []float32) {
+		emit(0, y, xMin, coverage)
+	})
+}
+
+// numBands returns the number of bands used to render height scanlines
+// with the active edge list. A value of 1 selects serial rendering.
+func (r *Rasterizer) numBands(height int) int {
+	if r.Workers < 2 {
+		return 1
+	}
+	n := min(r.Workers*bandsPerWorker, height/minBandHeight)
+	return max(n, 1)
+}
+
+// bandBytes returns the size in bytes of the buffers needed to render a
+// bounding box of the given size in n bands: the accumulation buffers of
+// the workers and, unless rows are delivered through r.bandEmit, the
+// stored rows of all bands.
+func (r *Rasterizer) bandBytes(width, height, n int) int {
+	bytes := 8 * width * min(r.Workers, n)
+	if r.bandEmit == nil {
+		bytes += 4 * width * height
+	}
+	return bytes
+}
+
+// bandRows returns the first and last+1 scanline of band k out of n.
+func bandRows(yMin, yMax, k, n int) (int, int) {
+	height := yMax - yMin
+	return yMin + k*height/n, yMin + (k+1)*height/n
+}
+
+// fillBands renders the bounding box in bands, using Approach B in each
+// band. The edges must be sorted by y_min. Rows are delivered through
+// r.bandEmit if set, and in scanline order through emit otherwise.
+func (r *Rasterizer) fillBands(xMin, xMax, yMin, yMax int, rule FillRule, emit func(y, xMin int, coverage []float32)) {
+	n := r.numBands(yMax - yMin)
+	job := &r.bands
+	job.xMin, job.xMax, job.yMin, job.yMax = xMin, xMax, yMin, yMax
+	job.rule = rule
+	job.n = n
+
+	if r.bandEmit != nil {
+		r.startBands()
+		job.wg.Wait()
+		return
+	}
+
+	for len(r.bandOutputs) < n {
+		r.bandOutputs = append(r.bandOutputs, &bandOutput{})
+	}
+	outputs := r.bandOutputs[:n]
+	for _, out := range outputs {
+		out.rows = out.rows[:0]
+		out.coverage = out.coverage[:0]
+		out.ready = false
+	}
+	if cap(job.done) < n {
+		job.done = make(chan int, n)
+	}
+
+	r.startBands()
+
+	// Deliver the bands in order, while later bands are still being
+	// rendered.
+	for k := 0; k < n; {
+		outputs[<-job.done].ready = true
+		for ; k < n && outputs[k].ready; k++ {
+			out := outputs[k]
+			for _, row := range out.rows {
+				emit(row.y, row.xMin, out.coverage[row.start:row.end])
+			}
+		}
+	}
+	job.wg.Wait()
+}
+
+// startBands starts up to r.Workers goroutines to render the bands
+// described by r.bands. The caller must wait for r.bands.wg.
+func (r *Rasterizer) startBands() {
+	job := &r.bands
+	workers := min(r.Workers, job.n)
+	for len(r.bandWorkers) < workers {
+		w := &bandWorker{r: r}
+		w.run = w.renderBands
+		w.emit = w.emitRow
+		r.bandWorkers = append(r.bandWorkers, w)
+	}
+
+	job.next.Store(0)
+	job.wg.Add(workers)
+	for _, w := range r.bandWorkers[:workers] {
+		go w.run()
+	}
+}
+
+// renderBands renders bands until none are left. Rows are passed to r.bandEmit
+// if set, and stored in r.bandOutputs otherwise.
+func (w *bandWorker) renderBands() {
+	r := w.r
+	job := &r.bands
+	defer job.wg.Done()
+	for {
+		k := int(job.next.Add(1)) - 1
+		if k >= job.n {
+			return
+		}
+		w.band = k
+		y0, y1 := bandRows(job.yMin, job.yMax, k, job.n)
+		w.active = r.scanRows(&w.cover, &w.area, w.active[:0], job.xMin, job.xMax, y0, y1, job.rule, w.emit)
+		if r.bandEmit == nil {
+			job.done <- k
+		}
+	}
+}
+
+// emitRow passes one row of the current band on.
+func (w *bandWorker) emitRow(y, xMin int, coverage []float32) {
+	if w.r.bandEmit != nil {
+		w.r.bandEmit(w.band, y, xMin, coverage)
+		return
+	}
+	out := w.r.bandOutputs[w.band]
+	start := len(out.coverage)
+	out.coverage = append(out.coverage, coverage...)
+	out.rows = append(out.rows, storedRow{y: y, xMin: xMin, start: start, end: len(out.coverage)})
+}
@@ -0,0 +1,171 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package raster
+
+import (
+	"math"
+	"sync"
+	"testing"
+
+	"seehuhn.de/go/geom/path"
+	"seehuhn.de/go/geom/rect"
+	"seehuhn.de/go/geom/vec"
+)
+
+// starPath returns a self-intersecting star with n points, centred in a
+// size×size square.
+func starPath(n int, size float64) *path.Data {
+	p := &path.Data{}
+	c := size / 2
+	for i := range n {
+		phi := 2 * math.Pi * float64(i*(n/2-1)) / float64(n)
+		pt := vec.Vec2{X: c + 0.45*size*math.Cos(phi), Y: c + 0.45*size*math.Sin(phi)}
+		if i == 0 {
+			p.MoveTo(pt)
+		} else {
+			p.LineTo(pt)
+		}
+	}
+	return p.Close()
+}
+
+func TestParallelBitIdentical(t *testing.T) {
+	const size = 300
+	star := starPath(37, size)
+	line := (&path.Data{}).
+		MoveTo(vec.Vec2{X: 10, Y: 10}).
+		CubeTo(vec.Vec2{X: 400, Y: 20}, vec.Vec2{X: -100, Y: 280}, vec.Vec2{X: 290, Y: 290})
+
+	draws := []struct {
+		name string
+		draw func(r *Rasterizer, emit func(y, xMin int, coverage []float32))
+	}{
+		{"nonzero", func(r *Rasterizer, emit func(y, xMin int, coverage []float32)) {
+			r.FillNonZero(star.Iter(), emit)
+		}},
+		{"evenodd", func(r *Rasterizer, emit func(y, xMin int, coverage []float32)) {
+			r.FillEvenOdd(star.Iter(), emit)
+		}},
+		{"stroke", func(r *Rasterizer, emit func(y, xMin int, coverage []float32)) {
+			r.Width = 7
+			r.Dash = []float64{20, 5}
+			r.Stroke(line.Iter(), emit)
+		}},
+		{"clipped", func(r *Rasterizer, emit func(y, xMin int, coverage []float32)) {
+			r.PushClipPath(starPath(11, size).Iter(), EvenOdd)
+			r.FillNonZero(star.Iter(), emit)
+		}},
+	}
+
+	for _, d := range draws {
+		render := func(workers int) []float32 {
+			r := NewRasterizer(rect.Rect{URx: size, URy: size})
+			r.smallPathThreshold = 0 // force Approach B
+			r.sparseCellRatio = 0
+			r.Workers = workers
+			buf := make([]float32, size*size)
+			lastY := -1
+			d.draw(r, func(y, xMin int, coverage []float32) {
+				if y <= lastY {
+					t.Errorf("%s, %d workers: row %d emitted after row %d", d.name, workers, y, lastY)
+				}
+				lastY = y
+				copy(buf[y*size+xMin:], coverage)
+			})
+			return buf
+		}
+
+		serial := render(1)
+		for _, workers := range []int{2, 3, 8} {
+			parallel := render(workers)
+			for i := range serial {
+				if serial[i] != parallel[i] {
+					t.Errorf("%s, %d workers: pixel (%d,%d) = %g, want %g",
+						d.name, workers, i%size, i/size, parallel[i], serial[i])
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestFillBands(t *testing.T) {
+	const size = 200
+	star := starPath(23, size)
+
+	r := NewRasterizer(rect.Rect{URx: size, URy: size})
+	r.smallPathThreshold = 0
+	r.sparseCellRatio = 0
+	want := renderCoverage(r, star, size, size)
+
+	r.Workers = 4
+	got := make([]float32, size*size)
+	var mu sync.Mutex
+	lastY := map[int]int{}
+	r.FillBands(star.Iter(), NonZero, func(band, y, xMin int, coverage []float32) {
+		mu.Lock()
+		defer mu.Unlock()
+		if prev, ok := lastY[band]; ok && y <= prev {
+			t.Errorf("band %d: row %d emitted after row %d", band, y, prev)
+		}
+		lastY[band] = y
+		copy(got[y*size+xMin:], coverage)
+	})
+
+	if len(lastY) < 2 {
+		t.Errorf("only %d bands used", len(lastY))
+	}
+	for band, y := range lastY {
+		if other, ok := lastY[band+1]; ok && other <= y {
+			t.Errorf("band %d ends at row %d, band %d at row %d", band, y, band+1, other)
+		}
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("pixel (%d,%d) = %g, want %g", i%size, i/size, got[i], want[i])
+		}
+	}
+
+	// serial rendering reports a single band
+	r.Workers = 0
+	r.FillBands(star.Iter(), EvenOdd, func(band, y, xMin int, coverage []float32) {
+		if band != 0 {
+			t.Fatalf("serial rendering: band %d", band)
+		}
+	})
+}
+
+func TestParallelAllocs(t *testing.T) {
+	// Once the buffers have grown, band rendering reuses the state of the
+	// workers and allocates no more than serial rendering.
+	const size = 200
+	star := starPath(23, size)
+	r := NewRasterizer(rect.Rect{URx: size, URy: size})
+	r.smallPathThreshold = 0
+	r.sparseCellRatio = 0
+	emit := func(y, xMin int, coverage []float32) {}
+	fill := func() { r.FillNonZero(star.Iter(), emit) }
+
+	fill()
+	serial := testing.AllocsPerRun(20, fill)
+	r.Workers = 4
+	fill()
+	parallel := testing.AllocsPerRun(20, fill)
+	if parallel > serial {
+		t.Errorf("%g allocations with bands, %g without", parallel, serial)
+	}
+}
@@ -41,6 +41,14 @@ type edge struct {
 // paths. Internal buffers grow as needed but never shrink, achieving zero
 // allocations in steady state.
 //
+// The fields are not checked by FillNonZero, FillEvenOdd and Stroke.
+// Use Validate, or the error-returning variants TryFillNonZero,
+// TryFillEvenOdd and TryStroke, when the settings or paths come from
+// untrusted input.
+//
+// Save and Restore keep a stack of graphics states, for interpreters of
+// the PDF operators q and Q.
+//
 // A Rasterizer is not safe for concurrent use.
 type Rasterizer struct {
 	// CTM transforms from user space to device space. Must be non-singular.
@@ -48,6 +56,7 @@ type Rasterizer struct {
 
 	// Clip bounds output to this device-coordinate rectangle.
 	// Coordinates must be integer-aligned.
+	// Use PushClipPath to clip to arbitrary paths.
 	Clip rect.Rect
 
 	// Flatness controls curve approximation accuracy in device pixels.
@@ -55,7 +64,9 @@ type Rasterizer struct {
 	Flatness float64
 
 	// Width sets stroke thickness in user-space units.
-	// Must be positive for stroke operations.
+	// Must be non-negative for stroke operations. Zero selects a hairline:
+	// the thinnest line that can be rendered, one device pixel wide
+	// regardless of CTM.
 	Width float64
 
 	// Cap sets the style for stroke endpoints (butt, round, or square).
@@ -76,13 +87,54 @@ type Rasterizer struct {
 	// Can be any value (positive, negative, or zero).
 	DashPhase float64
 
+	// StrokeAdjust enables automatic stroke adjustment, as selected by the
+	// SA entry of a PDF graphics state. Strokes are then built in device
+	// space, with the line width rounded to a whole number of pixels (at
+	// least one), and horizontal and vertical segments are moved so that
+	// their edges fall on pixel boundaries. This makes thin rules render
+	// with consistent width and full contrast. Strokes wider than three
+	// device pixels, and strokes under a CTM which scales different
+	// directions by factors differing by more than 1.5, are not adjusted.
+	StrokeAdjust bool
+
+	// LCDLayout selects the sub-pixel arrangement for FillNonZeroLCD,
+	// FillEvenOddLCD and StrokeLCD.
+	LCDLayout SubpixelLayout
+
+	// LCDFilter is the filter applied to sub-pixel coverage by the LCD
+	// rendering methods. The zero value selects DefaultLCDFilter.
+	LCDFilter LCDFilter
+
+	// Limits bounds the resources used by FillContext, StrokeContext,
+	// FillStrokeContext and PushClipPathContext. The zero value selects
+	// DefaultLimits.
+	Limits Limits
+
+	// Workers is the number of goroutines used to render large paths.
+	// If Workers is at least 2, large paths which touch many pixels are
+	// split into horizontal bands which are rendered concurrently; rows are still passed to the
+	// emit callback in order, on the calling goroutine. The results are
+	// bit-identical to serial rendering. See also FillBands.
+	//
+	// Only paths rendered with the active edge list are split into bands.
+	// Small paths, and large paths where only few pixels lie on edges, are
+	// always rendered serially, since this is faster for them.
+	Workers int
+
 	// smallPathThreshold is the maximum bounding box area (in pixels) for
 	// using 2D buffers (Approach A). Paths with larger bounding boxes use
 	// the active edge list (Approach B).
 	smallPathThreshold int
 
+	// sparseCellRatio is the maximum ratio between the estimated number of
+	// pixels touched by edges and the bounding box area for using sparse
+	// cells (Approach C). Zero disables Approach C.
+	sparseCellRatio float64
+
 	// Internal buffers (reused across calls)
 	cover         []float32  // coverage accumulation: cover change per pixel; reused as output
+	cells         []cell     // sparse coverage accumulation (Approach C)
+	spans         []Span     // output buffer for FillSpans
 	area          []float32  // coverage accumulation: area within pixel
 	edges         []edge     // edge list for current path (device coordinates)
 	activeIdx     []int      // indices of active edges
@@ -103,9 +155,46 @@ type Rasterizer struct {
 	edgeDevYMin   float64
 	edgeDevYMax   float64
 
+	// Culling of edges outside the clip region (see startEdges)
+	cull               bool    // whether edges outside the clip region are culled
+	cullRight          bool    // whether edges right of the clip region were dropped
+	cullXMin, cullXMax float64 // clip region in device space
+	cullYMin, cullYMax float64
+
 	// Dash pattern output buffers
 	dashedSegs        []strokeSegment // all dashed segments, contiguous
 	dashedSegsOffsets []int           // start index of each dashed subpath
+
+	// Clip path stack (see PushClipPath)
+	clipStack []clipMask // clip levels; entries from clipDepth on are kept for reuse
+	clipDepth int        // number of active clip levels
+
+	// Merging of fill and stroke coverage (see FillStroke)
+	mergeRows     []storedRow // rows of fill coverage
+	mergeCoverage []float32   // storage for mergeRows, contiguous
+	mergeRow      []float32   // one merged output row
+
+	// Cancellation and resource limits (see FillContext)
+	limited  bool            // whether limits and cancellation are active
+	limits   Limits          // limits in effect, with defaults filled in
+	done     <-chan struct{} // closed when the operation is cancelled
+	abortErr error           // the first limit which was exceeded
+
+	// Graphics state stack (see Save and Restore)
+	saveStack []savedState // saved states; entries from saveDepth on are kept for reuse
+	saveDepth int          // number of saved states
+	dashBuf   []float64    // storage for Dash after SetState
+
+	// Sub-pixel rendering buffers (see FillNonZeroLCD)
+	lcdRows     []storedRow // rows of sub-pixel coverage
+	lcdCoverage []float32   // storage for lcdRows, contiguous
+	lcdDense    []float32   // sub-pixel coverage as a 2D buffer
+
+	// Parallel band rendering (see Workers)
+	bands       bandJob                                // bands being rendered
+	bandWorkers []*bandWorker                          // per-goroutine state
+	bandOutputs []*bandOutput                          // per-band rows, for in-order delivery
+	bandEmit    func(band, y, xMin int, cov []float32) // set during FillBands
 }
 
 // NewRasterizer returns a Rasterizer with the given clip rectangle and
@@ -121,6 +210,7 @@ func NewRasterizer(clip rect.Rect) *Rasterizer {
 		MiterLimit: defaultMiterLimit,
 
 		smallPathThreshold: smallPathThreshold,
+		sparseCellRatio:    sparseCellRatio,
 	}
 }
 
@@ -137,18 +227,7 @@ func (r *Rasterizer) transformLinear(v vec.Vec2) vec.Vec2 {
 // p0 is the start point (current point), p1 is control, p2 is endpoint.
 // All points are in user space; CTM-aware tolerance checking is used.
 func (r *Rasterizer) flattenQuadratic(p0, p1, p2 vec.Vec2, emit func(from, to vec.Vec2)) {
-	// Compute error vector: e = (P0 - 2*P1 + P2) / 4
-	e := p0.Sub(p1.Mul(2)).Add(p2).Mul(0.25)
-
-	// Transform to device space
-	eDev := r.transformLinear(e)
-
-	// Compute segment count
-	n := 1
-	errDev := eDev.Length()
-	if errDev > r.Flatness {
-		n = int(math.Ceil(math.Sqrt(errDev / r.Flatness)))
-	}
+	n := int(r.quadraticSegments(p0, p1, p2))
 
 	// Evaluate curve at n+1 points and emit segments
 	prev := p0
@@ -165,24 +244,7 @@ func (r *Rasterizer) flattenQuadratic(p0, p1, p2 vec.Vec2, emit func(from, to ve
 // flattenCubic flattens a cubic Bézier and calls emit for each line segment.
 // p0 is start, p1/p2 are controls, p3 is endpoint. All in user space.
 func (r *Rasterizer) flattenCubic(p0, p1, p2, p3 vec.Vec2, emit func(from, to vec.Vec2)) {
-	// Compute deviation vectors
-	d1 := p0.Sub(p1.Mul(2)).Add(p2) // P0 - 2*P1 + P2
-	d2 := p1.Sub(p2.Mul(2)).Add(p3) // P1 - 2*P2 + P3
-
-	// Transform to device space
-	d1Dev := r.transformLinear(d1)
-	d2Dev := r.transformLinear(d2)
-
-	// Compute segment count using Wang's formula
-	mDev := max(d1Dev.Length(), d2Dev.Length())
-	n := 1
-	if mDev > 0 {
-		// n = ceil(sqrt(3 * mDev / (4 * ε)))
-		nFloat := math.Sqrt(3 * mDev / (4 * r.Flatness))
-		if nFloat > 1 {
-			n = int(math.Ceil(nFloat))
-		}
-	}
+	n := int(r.cubicSegments(p0, p1, p2, p3))
 
 	// Evaluate curve at n+1 points and emit segments
 	prev := p0
@@ -200,59 +262,123 @@ func (r *Rasterizer) flattenCubic(p0, p1, p2, p3 vec.Vec2, emit func(from, to ve
 	}
 }
 
+// quadraticSegments returns the number of line segments used to flatten a
+// quadratic Bézier curve. The result is returned as a float64, so that
+// callers can check for absurd values before converting to int.
+func (r *Rasterizer) quadraticSegments(p0, p1, p2 vec.Vec2) float64 {
+	// Compute error vector: e = (P0 - 2*P1 + P2) / 4
+	e := p0.Sub(p1.Mul(2)).Add(p2).Mul(0.25)
+
+	// Transform to device space
+	errDev := r.transformLinear(e).Length()
+	if errDev > r.Flatness {
+		return math.Ceil(math.Sqrt(errDev / r.Flatness))
+	}
+	return 1
+}
+
+// cubicSegments returns the number of line segments used to flatten a
+// cubic Bézier curve, using Wang's formula.
+func (r *Rasterizer) cubicSegments(p0, p1, p2, p3 vec.Vec2) float64 {
+	// Compute deviation vectors
+	d1 := p0.Sub(p1.Mul(2)).Add(p2) // P0 - 2*P1 + P2
+	d2 := p1.Sub(p2.Mul(2)).Add(p3) // P1 - 2*P2 + P3
+
+	// Transform to device space
+	mDev := max(r.transformLinear(d1).Length(), r.transformLinear(d2).Length())
+	if mDev > 0 {
+		// n = ceil(sqrt(3 * mDev / (4 * ε)))
+		nFloat := math.Sqrt(3 * mDev / (4 * r.Flatness))
+		if nFloat > 1 {
+			return math.Ceil(nFloat)
+		}
+	}
+	return 1
+}
+
 // FillNonZero fills the path using the nonzero winding rule. The emit
 // callback receives coverage row-by-row; its slice argument is valid only
 // during the call.
 func (r *Rasterizer) FillNonZero(p path.Path, emit func(y, xMin int, coverage []float32)) {
-	r.fill(p, fillNonZero, emit)
+	r.fill(p, NonZero, emit)
 }
 
 // FillEvenOdd fills the path using the even-odd rule. The emit callback
 // receives coverage row-by-row; its slice argument is valid only during
 // the call.
 func (r *Rasterizer) FillEvenOdd(p path.Path, emit func(y, xMin int, coverage []float32)) {
-	r.fill(p, fillEvenOdd, emit)
+	r.fill(p, EvenOdd, emit)
 }
 
-// fillRule identifies which fill rule to apply.
-type fillRule int
+// FillRule selects how the interior of a path is determined.
+type FillRule int
 
 const (
-	fillNonZero fillRule = iota
-	fillEvenOdd
+	// NonZero is the nonzero winding number rule.
+	NonZero FillRule = iota
+
+	// EvenOdd is the even-odd rule.
+	EvenOdd
 )
 
 // fill is the internal implementation shared by FillNonZero and FillEvenOdd.
-func (r *Rasterizer) fill(p path.Path, rule fillRule, emit func(y, xMin int, coverage []float32)) {
+func (r *Rasterizer) fill(p path.Path, rule FillRule, emit func(y, xMin int, coverage []float32)) {
 	// Collect edges from path (returns bounding box clamped to clip)
-	xMin, xMax, yMin, yMax, ok := r.collectPathEdges(p)
+	xMin, xMax, yMin, yMax, ok := r.collectPathEdges(p, true)
 	if !ok {
 		return // empty or degenerate path
 	}
 
-	// Choose approach based on bounding box size
-	width := xMax - xMin
-	height := yMax - yMin
+	r.fillEdges(xMin, xMax, yMin, yMax, rule, emit)
+}
+
+// fillEdges rasterises the collected edges, choosing an approach based on
+// the bounding box size and the number of pixels touched by the edges:
+// Approach A for small bounding boxes, Approach C for large bounding boxes
+// where only few pixels lie on edges, and Approach B otherwise. Approach C
+// takes precedence over parallel bands (see Workers), which are only used
+// within Approach B.
+//
+// If limits are active, Approach A is only used if its buffers fit into
+// Limits.MaxBufferBytes.
+func (r *Rasterizer) fillEdges(xMin, xMax, yMin, yMax int, rule FillRule, emit func(y, xMin int, coverage []float32)) {
+	if r.aborted() {
+		return
+	}
 
-	if width*height < r.smallPathThreshold {
+	size := (xMax - xMin) * (yMax - yMin)
+	switch {
+	case size < r.smallPathThreshold && r.bufferFits(8*size):
 		r.fillSmallPath(xMin, xMax, yMin, yMax, rule, emit)
-	} else {
+	case r.useSparse(yMin, yMax, size):
+		r.fillSparsePath(xMin, xMax, yMin, yMax, rule, emit)
+	case !r.bufferFits(8 * (xMax - xMin)):
+		r.abort("MaxBufferBytes", r.limits.MaxBufferBytes)
+	default:
 		r.fillLargePath(xMin, xMax, yMin, yMax, rule, emit)
 	}
 }
 
 // collectPathEdges walks the path, transforms to device space, and builds the edge list.
+// If cull is set, edges outside the clip region are culled as described for
+// startEdges.
 // Returns the bounding box of all edges in device coordinates (clamped to clip).
-func (r *Rasterizer) collectPathEdges(p path.Path) (xMin, xMax, yMin, yMax int, ok bool) {
-	r.edges = r.edges[:0]
-	r.edgeBBoxFirst = true
+func (r *Rasterizer) collectPathEdges(p path.Path, cull bool) (xMin, xMax, yMin, yMax int, ok bool) {
+	r.startEdges(cull)
 
 	// path state
 	var current vec.Vec2 // current point (user space)
 	var subpath vec.Vec2 // subpath start (user space)
 	hasSubpath := false
 
+	// A return statement inside the loop body would make the results
+	// escape to the heap, so the loop is left with break instead.
+	aborted := false
 	for cmd, pts := range p {
+		if r.aborted() {
+			aborted = true
+			break
+		}
 		switch cmd {
 		case path.CmdMoveTo:
 			// implicitly close previous subpath
@@ -268,11 +394,19 @@ func (r *Rasterizer) collectPathEdges(p path.Path) (xMin, xMax, yMin, yMax int,
 			current = pts[0]
 
 		case path.CmdQuadTo:
-			r.flattenQuadratic(current, pts[0], pts[1], r.addEdge)
+			if r.cull && r.hullOutside(current, pts[0], pts[1], pts[1]) {
+				r.addEdge(current, pts[1])
+			} else {
+				r.flattenQuadratic(current, pts[0], pts[1], r.addEdge)
+			}
 			current = pts[1]
 
 		case path.CmdCubeTo:
-			r.flattenCubic(current, pts[0], pts[1], pts[2], r.addEdge)
+			if r.cull && r.hullOutside(current, pts[0], pts[1], pts[2]) {
+				r.addEdge(current, pts[2])
+			} else {
+				r.flattenCubic(current, pts[0], pts[1], pts[2], r.addEdge)
+			}
 			current = pts[2]
 
 		case path.CmdClose:
@@ -284,6 +418,10 @@ func (r *Rasterizer) collectPathEdges(p path.Path) (xMin, xMax, yMin, yMax int,
 		}
 	}
 
+	if aborted {
+		return 0, 0, 0, 0, false
+	}
+
 	// implicitly close final subpath
 	if hasSubpath && current != subpath {
 		r.addEdge(current, subpath)
@@ -293,14 +431,59 @@ func (r *Rasterizer) collectPathEdges(p path.Path) (xMin, xMax, yMin, yMax int,
 		return 0, 0, 0, 0, false
 	}
 
-	// clamp to clip bounds and convert to integers
-	clipXMin := int(r.Clip.LLx)
-	clipXMax := int(r.Clip.URx)
-	clipYMin := int(r.Clip.LLy)
-	clipYMax := int(r.Clip.URy)
+	return r.edgeBounds()
+}
+
+// startEdges clears the edge list. If cull is set, the edges added by
+// addEdge are culled against the clip region, keeping the cost of
+// rendering proportional to the visible part of a path: edges above,
+// below or right of the clip region are dropped, and edges left of it are
+// moved onto its left boundary, where consecutive ones merge into a single
+// vertical edge. The coverage inside the clip region is unchanged, but the
+// edge list no longer describes the path outside of it.
+func (r *Rasterizer) startEdges(cull bool) {
+	r.edges = r.edges[:0]
+	r.edgeBBoxFirst = true
+	r.setCull(cull)
+}
+
+// setCull enables or disables culling against the current clip region.
+func (r *Rasterizer) setCull(cull bool) {
+	r.cull = cull
+	r.cullRight = false
+	if cull {
+		xMin, xMax, yMin, yMax := r.clipBounds()
+		r.cullXMin, r.cullXMax = float64(xMin), float64(xMax)
+		r.cullYMin, r.cullYMax = float64(yMin), float64(yMax)
+	}
+}
+
+// hullOutside reports whether the device-space bounding box of the given
+// user-space points lies entirely above, below, left or right of the clip
+// region. A curve inside this box can then be replaced by its chord, since
+// addEdge drops or collapses both in the same way.
+func (r *Rasterizer) hullOutside(p0, p1, p2, p3 vec.Vec2) bool {
+	x0, y0 := r.CTM.Apply(p0.X, p0.Y)
+	xMin, xMax, yMin, yMax := x0, x0, y0, y0
+	for _, p := range [3]vec.Vec2{p1, p2, p3} {
+		x, y := r.CTM.Apply(p.X, p.Y)
+		xMin, xMax = min(xMin, x), max(xMax, x)
+		yMin, yMax = min(yMin, y), max(yMax, y)
+	}
+	return yMax <= r.cullYMin || yMin >= r.cullYMax ||
+		xMin >= r.cullXMax || xMax <= r.cullXMin
+}
+
+// edgeBounds converts the device-space bounding box of the collected edges
+// to integer pixel bounds, clamped to Clip and to the active clip path.
+func (r *Rasterizer) edgeBounds() (xMin, xMax, yMin, yMax int, ok bool) {
+	clipXMin, clipXMax, clipYMin, clipYMax := r.clipBounds()
 
 	xMin = max(int(math.Floor(r.edgeDevXMin)), clipXMin)
 	xMax = min(int(math.Floor(r.edgeDevXMax))+1, clipXMax)
+	if r.cullRight {
+		xMax = clipXMax
+	}
 	yMin = max(int(math.Floor(r.edgeDevYMin)), clipYMin)
 	yMax = min(int(math.Floor(r.edgeDevYMax))+1, clipYMax)
 
@@ -325,6 +508,41 @@ func (r *Rasterizer) addEdge(p0, p1 vec.Vec2) {
 		return
 	}
 
+	if r.cull {
+		switch {
+		case max(dy0, dy1) <= r.cullYMin || min(dy0, dy1) >= r.cullYMax:
+			// no visible scanlines
+			return
+		case min(dx0, dx1) >= r.cullXMax:
+			// Accumulation runs from left to right, so the edge has no
+			// effect, but the coverage of the path may extend to the
+			// right boundary of the clip region.
+			r.cullRight = true
+			return
+		case max(dx0, dx1) <= r.cullXMin:
+			// The whole cover is accumulated in the leftmost column, as
+			// for a vertical edge on the boundary.
+			dx0, dx1 = r.cullXMin, r.cullXMin
+			if n := len(r.edges); n > 0 {
+				last := &r.edges[n-1]
+				if last.x0 == dx0 && last.x1 == dx0 && last.y1 == dy0 {
+					last.y1 = dy1
+					r.edgeDevYMin = min(r.edgeDevYMin, dy1)
+					r.edgeDevYMax = max(r.edgeDevYMax, dy1)
+					if d := last.y1 - last.y0; d > -horizontalEdgeThreshold && d < horizontalEdgeThreshold {
+						r.edges = r.edges[:n-1]
+					}
+					return
+				}
+			}
+		}
+	}
+
+	if r.limited && len(r.edges) >= r.limits.MaxEdges {
+		r.abort("MaxEdges", r.limits.MaxEdges)
+		return
+	}
+
 	// Compute dxdy
 	dxdy := (dx1 - dx0) / dy
 
@@ -366,11 +584,33 @@ func (r *Rasterizer) addEdge(p0, p1 vec.Vec2) {
 // This computes the signed area of the path within each pixel, which gives
 // anti-aliased coverage values when clamped to [0,1] (nonzero) or folded (even-odd).
 
-// accumulateEdge adds a single edge's contribution to the cover and area buffers.
-// The buffers are indexed by (x - bboxXMin), where bboxXMin/bboxXMax define the buffer range.
-// For edges spanning multiple pixels horizontally, this function splits the edge at pixel
-// boundaries and computes separate contributions for each pixel crossed.
-func (r *Rasterizer) accumulateEdge(e *edge, y int, cover, area []float32, bboxXMin, bboxXMax int) {
+// edgeSink receives the contributions computed by accumulateEdge.
+type edgeSink interface {
+	// add adds cover and area to pixel x of the scanline, where
+	// bboxXMin ≤ x < bboxXMax.
+	add(x int, cover, area float32)
+}
+
+// rowSink adds contributions to cover and area buffers, indexed by
+// (x - xMin). It is used by Approaches A and B.
+type rowSink struct {
+	cover, area []float32
+	xMin        int
+}
+
+func (s rowSink) add(x int, cover, area float32) {
+	idx := x - s.xMin
+	s.cover[idx] += cover
+	s.area[idx] += area
+}
+
+// accumulateEdge computes a single edge's contribution to scanline y and
+// passes it to sink. For edges spanning multiple pixels horizontally, this
+// function splits the edge at pixel boundaries and computes separate
+// contributions for each pixel crossed. Contributions left of the
+// bounding box are added to pixel bboxXMin, with area equal to cover;
+// contributions right of the bounding box are dropped.
+func accumulateEdge[S edgeSink](e *edge, y int, bboxXMin, bboxXMax int, sink S) {
 	// Compute the portion of the edge within this scanline [y, y+1)
 	yTop := float64(y)
 	yBot := float64(y + 1)
@@ -407,8 +647,7 @@ func (r *Rasterizer) accumulateEdge(e *edge, y int, cover, area []float32, bboxX
 	// Handle edge entirely to the left of bbox
 	if pixRight < bboxXMin {
 		coverVal := sign * float32(yBot-yTop)
-		cover[0] += coverVal
-		area[0] += coverVal
+		sink.add(bboxXMin, coverVal, coverVal)
 		return
 	}
 
@@ -419,7 +658,7 @@ func (r *Rasterizer) accumulateEdge(e *edge, y int, cover, area []float32, bboxX
 
 	// For vertical edges or edges within a single pixel column
 	if pixLeft == pixRight {
-		r.accumulateEdgeInColumn(e, yTop, yBot, sign, pixLeft, cover, area, bboxXMin, bboxXMax)
+		accumulateEdgeInColumn(e, yTop, yBot, sign, pixLeft, bboxXMin, bboxXMax, sink)
 		return
 	}
 
@@ -450,26 +689,22 @@ func (r *Rasterizer) accumulateEdge(e *edge, y int, cover, area []float32, bboxX
 		xFrac := xMid - float64(pix)
 		areaVal := coverVal * float32(1-xFrac)
 
-		// Add to buffers
+		// Pass on the contribution
 		if pix < bboxXMin {
-			cover[0] += coverVal
-			area[0] += coverVal
+			sink.add(bboxXMin, coverVal, coverVal)
 		} else if pix < bboxXMax {
-			idx := pix - bboxXMin
-			cover[idx] += coverVal
-			area[idx] += areaVal
+			sink.add(pix, coverVal, areaVal)
 		}
 		// pix >= bboxXMax: no contribution
 	}
 }
 
 // accumulateEdgeInColumn handles an edge segment that falls within a single pixel column.
-func (r *Rasterizer) accumulateEdgeInColumn(e *edge, yTop, yBot float64, sign float32, pix int, cover, area []float32, bboxXMin, bboxXMax int) {
+func accumulateEdgeInColumn[S edgeSink](e *edge, yTop, yBot float64, sign float32, pix int, bboxXMin, bboxXMax int, sink S) {
 	coverVal := sign * float32(yBot-yTop)
 
 	if pix < bboxXMin {
-		cover[0] += coverVal
-		area[0] += coverVal
+		sink.add(bboxXMin, coverVal, coverVal)
 		return
 	}
 	if pix >= bboxXMax {
@@ -482,9 +717,7 @@ func (r *Rasterizer) accumulateEdgeInColumn(e *edge, yTop, yBot float64, sign fl
 	xFrac := xMid - float64(pix)
 	areaVal := coverVal * float32(1-xFrac)
 
-	idx := pix - bboxXMin
-	cover[idx] += coverVal
-	area[idx] += areaVal
+	sink.add(pix, coverVal, areaVal)
 }
 
 // integrateScanlineNonZero converts accumulated cover/area to final coverage
@@ -494,16 +727,7 @@ func integrateScanlineNonZero(cover, area []float32) {
 	for i := range cover {
 		raw := accum + area[i]
 		accum += cover[i]
-
-		// clamp(abs(raw), 0, 1)
-		cov := raw
-		if raw < 0 {
-			cov = -raw
-		}
-		if cov > 1 {
-			cov = 1
-		}
-		cover[i] = cov
+		cover[i] = nonZeroCoverage(raw)
 	}
 }
 
@@ -514,16 +738,34 @@ func integrateScanlineEvenOdd(cover, area []float32) {
 	for i := range cover {
 		raw := accum + area[i]
 		accum += cover[i]
+		cover[i] = evenOddCoverage(raw)
+	}
+}
 
-		// 1 - abs(1 - mod(abs(raw), 2))
-		if raw < 0 {
-			raw = -raw
-		}
-		// mod(raw, 2) using floor
-		mod := raw - 2*float32(int(raw/2))
-		cov := 1 - abs32(1-mod)
-		cover[i] = cov
+// nonZeroCoverage converts a signed area to coverage using the nonzero
+// winding rule.
+func nonZeroCoverage(raw float32) float32 {
+	// clamp(abs(raw), 0, 1)
+	cov := raw
+	if raw < 0 {
+		cov = -raw
+	}
+	if cov > 1 {
+		cov = 1
 	}
+	return cov
+}
+
+// evenOddCoverage converts a signed area to coverage using the even-odd
+// fill rule.
+func evenOddCoverage(raw float32) float32 {
+	// 1 - abs(1 - mod(abs(raw), 2))
+	if raw < 0 {
+		raw = -raw
+	}
+	// mod(raw, 2) using floor
+	mod := raw - 2*float32(int(raw/2))
+	return 1 - abs32(1-mod)
 }
 
 // abs32 returns the absolute value of a float32.
@@ -555,7 +797,7 @@ func trimZeros(coverage []float32) (trimmed []float32, offset int) {
 // fillSmallPath rasterises using 2D buffers (Approach A).
 // Used for small paths where width*height < smallPathThreshold.
 // xMin, xMax, yMin, yMax define the path's bounding box (already clamped to clip).
-func (r *Rasterizer) fillSmallPath(xMin, xMax, yMin, yMax int, rule fillRule, emit func(y, xMin int, coverage []float32)) {
+func (r *Rasterizer) fillSmallPath(xMin, xMax, yMin, yMax int, rule FillRule, emit func(y, xMin int, coverage []float32)) {
 	width := xMax - xMin
 	height := yMax - yMin
 
@@ -572,6 +814,9 @@ func (r *Rasterizer) fillSmallPath(xMin, xMax, yMin, yMax int, rule fillRule, em
 
 	// Process all edges into 2D buffers
 	for i := range r.edges {
+		if r.canceled() {
+			return
+		}
 		e := &r.edges[i]
 
 		// Determine scanline range for this edge
@@ -590,13 +835,16 @@ func (r *Rasterizer) fillSmallPath(xMin, xMax, yMin, yMax int, rule fillRule, em
 		for y := edgeYMin; y < edgeYMax; y++ {
 			row := y - yMin
 			rowOffset := row * width
-			r.accumulateEdge(e, y, r.cover[rowOffset:rowOffset+width], r.area[rowOffset:rowOffset+width], xMin, xMax)
+			accumulateEdge(e, y, xMin, xMax, rowSink{r.cover[rowOffset : rowOffset+width], r.area[rowOffset : rowOffset+width], xMin})
 			r.rowHasEdges[row] = true
 		}
 	}
 
 	// Integrate and emit each row
 	for row := range height {
+		if r.canceled() {
+			return
+		}
 		if !r.rowHasEdges[row] {
 			continue // no edges touched this row
 		}
@@ -606,42 +854,75 @@ func (r *Rasterizer) fillSmallPath(xMin, xMax, yMin, yMax int, rule fillRule, em
 
 		// Integrate the full width (cover accumulates from left)
 		coverage := r.cover[rowOffset : rowOffset+width]
-		if rule == fillNonZero {
+		if rule == NonZero {
 			integrateScanlineNonZero(coverage, r.area[rowOffset:rowOffset+width])
 		} else {
 			integrateScanlineEvenOdd(coverage, r.area[rowOffset:rowOffset+width])
 		}
 
-		// Emit only the non-zero portion
-		if trimmed, offset := trimZeros(coverage); trimmed != nil {
-			emit(y, xMin+offset, trimmed)
-		}
+		r.emitRow(y, xMin, coverage, emit)
 	}
 }
 
 // fillLargePath rasterises using 1D buffers and an active edge list (Approach B).
 // Used for large paths where width*height >= smallPathThreshold.
 // xMin, xMax, yMin, yMax define the path's bounding box (already clamped to clip).
-func (r *Rasterizer) fillLargePath(xMin, xMax, yMin, yMax int, rule fillRule, emit func(y, xMin int, coverage []float32)) {
-	width := xMax - xMin
+// If Workers is at least 2, the scanlines are rendered in parallel bands.
+func (r *Rasterizer) fillLargePath(xMin, xMax, yMin, yMax int, rule FillRule, emit func(y, xMin int, coverage []float32)) {
+	r.sortEdges()
 
-	// Ensure 1D buffers are large enough
-	r.cover = slices.Grow(r.cover[:0], width)[:width]
-	r.area = slices.Grow(r.area[:0], width)[:width]
+	if n := r.numBands(yMax - yMin); n > 1 && r.bufferFits(r.bandBytes(xMax-xMin, yMax-yMin, n)) {
+		r.fillBands(xMin, xMax, yMin, yMax, rule, emit)
+		return
+	}
+
+	r.activeIdx = r.scanRows(&r.cover, &r.area, r.activeIdx[:0], xMin, xMax, yMin, yMax, rule, emit)
+}
 
-	// Sort edges by y_min
+// sortEdges sorts the edges by y_min.
+func (r *Rasterizer) sortEdges() {
 	slices.SortFunc(r.edges, func(a, b edge) int {
 		aYMin := min(a.y0, a.y1)
 		bYMin := min(b.y0, b.y1)
 		return cmp.Compare(aYMin, bYMin)
 	})
+}
 
-	// Active edge list (indices into r.edges)
-	r.activeIdx = r.activeIdx[:0]
-	nextEdge := 0
+// scanRows runs the active edge list algorithm for the scanlines
+// yMin ≤ y < yMax. The edges must be sorted by y_min. The cover and area
+// buffers are grown as needed, and active is used as the active edge list;
+// the (possibly grown) list is returned for reuse.
+//
+// The active edge list is kept in edge order, so that the coverage of each
+// row is accumulated in the same order no matter at which row the scan
+// starts. This makes the output of parallel bands bit-identical to a
+// serial scan.
+func (r *Rasterizer) scanRows(coverBuf, areaBuf *[]float32, active []int, xMin, xMax, yMin, yMax int, rule FillRule, emit func(y, xMin int, coverage []float32)) []int {
+	width := xMax - xMin
+
+	// Ensure 1D buffers are large enough
+	cover := slices.Grow((*coverBuf)[:0], width)[:width]
+	area := slices.Grow((*areaBuf)[:0], width)[:width]
+	*coverBuf, *areaBuf = cover, area
+
+	// Active edge list (indices into r.edges): edges which start before
+	// this band and are still active at its first row
+	yf0 := float64(yMin)
+	nextEdge, _ := slices.BinarySearchFunc(r.edges, yf0, func(e edge, y float64) int {
+		return cmp.Compare(min(e.y0, e.y1), y)
+	})
+	for i := range nextEdge {
+		e := &r.edges[i]
+		if max(e.y0, e.y1) > yf0 {
+			active = append(active, i)
+		}
+	}
 
 	// Process scanlines
 	for y := yMin; y < yMax; y++ {
+		if r.canceled() {
+			break
+		}
 		yf := float64(y)
 		yfNext := float64(y + 1)
 
@@ -652,41 +933,42 @@ func (r *Rasterizer) fillLargePath(xMin, xMax, yMin, yMax int, rule fillRule, em
 			if edgeYMin >= yfNext {
 				break
 			}
-			r.activeIdx = append(r.activeIdx, nextEdge)
+			active = append(active, nextEdge)
 			nextEdge++
 		}
 
-		if len(r.activeIdx) == 0 {
+		if len(active) == 0 {
 			continue
 		}
 
 		// Clear buffers for this scanline
-		clear(r.cover)
-		clear(r.area)
+		clear(cover)
+		clear(area)
 
 		// Track x bounds for this scanline
 		xMinBound := width
 		xMaxBound := -1
 
 		// Process active edges
-		for i := 0; i < len(r.activeIdx); {
-			e := &r.edges[r.activeIdx[i]]
+		n := 0
+		for _, idx := range active {
+			e := &r.edges[idx]
 
-			// Check if edge ends before this scanline
+			// Remove edges which end before this scanline, preserving
+			// the order of the remaining edges
 			edgeYMax := max(e.y0, e.y1)
 			if edgeYMax <= yf {
-				// Remove from active list (swap with last)
-				r.activeIdx[i] = r.activeIdx[len(r.activeIdx)-1]
-				r.activeIdx = r.activeIdx[:len(r.activeIdx)-1]
 				continue
 			}
+			active[n] = idx
+			n++
 
 			// Accumulate contribution
-			r.accumulateEdge(e, y, r.cover, r.area, xMin, xMax)
+			accumulateEdge(e, y, xMin, xMax, rowSink{cover, area, xMin})
 
 			// Update x bounds
 			yTop := max(yf, min(e.y0, e.y1))
-			yBot := min(yfNext, max(e.y0, e.y1))
+			yBot := min(yfNext, edgeYMax)
 			if yBot > yTop {
 				yMid := (yTop + yBot) / 2
 				xMidF := e.x0 + e.dxdy*(yMid-e.y0)
@@ -701,26 +983,23 @@ func (r *Rasterizer) fillLargePath(xMin, xMax, yMin, yMax int, rule fillRule, em
 					xMaxBound = xIdx
 				}
 			}
-
-			i++
 		}
+		active = active[:n]
 
 		if xMaxBound < 0 {
 			continue // no edges contributed to this scanline
 		}
 
 		// Integrate and emit
-		if rule == fillNonZero {
-			integrateScanlineNonZero(r.cover, r.area)
+		if rule == NonZero {
+			integrateScanlineNonZero(cover, area)
 		} else {
-			integrateScanlineEvenOdd(r.cover, r.area)
+			integrateScanlineEvenOdd(cover, area)
 		}
 
-		// Emit only the non-zero portion
-		if trimmed, offset := trimZeros(r.cover); trimmed != nil {
-			emit(y, xMin+offset, trimmed)
-		}
+		r.emitRow(y, xMin, cover, emit)
 	}
+	return active
 }
 
 // Default values for rasterizer parameters.
@@ -745,10 +1024,15 @@ const (
 
 	// smallPathThreshold is the maximum bounding box area (in pixels) for
 	// using 2D buffers (Approach A). Paths with larger bounding boxes use
-	// the active edge list (Approach B).
-	// TODO: tune this threshold based on profiling
+	// sparse cells (Approach C) or the active edge list (Approach B).
 	smallPathThreshold = 65536
 
+	// sparseCellRatio is the maximum ratio between the estimated number of
+	// cells and the bounding box area for using Approach C. Sorting and
+	// merging a cell costs roughly as much as integrating a few dozen
+	// pixels of a dense row.
+	sparseCellRatio = 1.0 / 32
+
 	// zeroLengthThreshold is the minimum length for a stroke segment.
 	// Segments shorter than this are skipped.
 	zeroLengthThreshold = 1e-10
@@ -760,4 +1044,8 @@ const (
 	// cuspCosineThreshold is the cosine threshold for detecting cusps
 	// (path doubling back on itself). cos(179.43°) ≈ -0.9999
 	cuspCosineThreshold = -0.9999
+
+	// axisAlignedThreshold is the maximum slope for a device-space stroke
+	// segment to count as horizontal or vertical for stroke adjustment.
+	axisAlignedThreshold = 1e-6
 )
@@ -38,15 +38,18 @@ import (
 )
 
 func TestAgainstReference(t *testing.T) {
-	// Test each case with both approaches:
+	// Test each case with all three approaches:
 	// - Approach A (2D buffers): threshold = MaxInt (always use A)
-	// - Approach B (active edge list): threshold = 0 (always use B)
+	// - Approach B (active edge list): threshold = 0, no sparse cells
+	// - Approach C (sparse cells): threshold = 0, unlimited sparse cells
 	approaches := []struct {
-		name      string
-		threshold int
+		name        string
+		threshold   int
+		sparseRatio float64
 	}{
-		{"A", 1 << 30}, // very large threshold forces Approach A
-		{"B", 0},       // zero threshold forces Approach B
+		{"A", 1 << 30, 0},     // very large threshold forces Approach A
+		{"B", 0, 0},           // zero threshold forces Approach B
+		{"C", 0, math.Inf(1)}, // infinite ratio forces Approach C
 	}
 
 	for _, category := range slices.Sorted(maps.Keys(testcases.All)) {
@@ -54,7 +57,7 @@ func TestAgainstReference(t *testing.T) {
 			baseName := category + "_" + tc.Name
 			for _, approach := range approaches {
 				name := baseName + "_" + approach.name
-				threshold := approach.threshold
+				threshold, sparseRatio := approach.threshold, approach.sparseRatio
 				t.Run(name, func(t *testing.T) {
 					// load reference image
 					refPath := filepath.Join("testdata", "reference", baseName+".png")
@@ -67,8 +70,8 @@ func TestAgainstReference(t *testing.T) {
 					w, h := tc.Width, tc.Height
 					actual := make([]byte, w*h)
 
-					// render with specified approach threshold
-					renderExample(tc, actual, w, h, w, threshold)
+					// render with specified approach thresholds
+					renderExample(tc, actual, w, h, w, threshold, sparseRatio)
 
 					// compare
 					if err := compareImages(name, ref, actual, w, h); err != nil {
@@ -83,8 +86,9 @@ func TestAgainstReference(t *testing.T) {
 // renderExample renders a test case into a grayscale buffer.
 // The buffer is pre-initialized with zeros, in row-major order.
 // Each byte represents coverage from 0 (transparent) to 255 (opaque).
-// The threshold parameter controls the Approach A/B cutoff for testing.
-func renderExample(tc testcases.TestCase, buf []byte, width, height, stride int, threshold int) {
+// The threshold and sparseRatio parameters control the choice between
+// Approaches A, B and C for testing.
+func renderExample(tc testcases.TestCase, buf []byte, width, height, stride int, threshold int, sparseRatio float64) {
 	clip := rect.Rect{
 		LLx: 0,
 		LLy: 0,
@@ -93,6 +97,7 @@ func renderExample(tc testcases.TestCase, buf []byte, width, height, stride int,
 	}
 	r := NewRasterizer(clip)
 	r.smallPathThreshold = threshold
+	r.sparseCellRatio = sparseRatio
 
 	// Apply CTM (zero-value means identity, which is already the default)
 	if tc.CTM != (matrix.Matrix{}) {
@@ -122,6 +127,7 @@ func renderExample(tc testcases.TestCase, buf []byte, width, height, stride int,
 		r.MiterLimit = op.MiterLimit
 		r.Dash = op.Dash
 		r.DashPhase = op.DashPhase
+		r.StrokeAdjust = op.StrokeAdjust
 		r.Stroke(tc.Path.Iter(), emit)
 	}
 }
@@ -284,6 +290,96 @@ func TestTriangleCoverage(t *testing.T) {
 	}
 }
 
+func TestCullEdges(t *testing.T) {
+	const size = 80
+	clip := rect.Rect{LLx: 20, LLy: 25, URx: 60, URy: 50}
+
+	blob := (&path.Data{}).
+		MoveTo(vec.Vec2{X: 5, Y: 40}).
+		CubeTo(vec.Vec2{X: 5, Y: -20}, vec.Vec2{X: 90, Y: 10}, vec.Vec2{X: 75, Y: 40}).
+		QuadTo(vec.Vec2{X: 60, Y: 100}, vec.Vec2{X: 40, Y: 70}).
+		CubeTo(vec.Vec2{X: 0, Y: 90}, vec.Vec2{X: -30, Y: 60}, vec.Vec2{X: 5, Y: 40}).
+		Close()
+	paths := []*path.Data{starPath(23, size), blob, rectPath(-10, -10, 100, 100)}
+	ctms := []matrix.Matrix{
+		matrix.Identity,
+		matrix.Rotate(0.3).Mul(matrix.Translate(10, -5)),
+		matrix.Scale(3, 2).Mul(matrix.Translate(-60, -40)),
+	}
+
+	for _, threshold := range []int{1 << 30, 0} {
+		for i, p := range paths {
+			for j, ctm := range ctms {
+				for _, rule := range []FillRule{NonZero, EvenOdd} {
+					r := NewRasterizer(clip)
+					r.smallPathThreshold = threshold
+					r.CTM = ctm
+
+					want := make([]float32, size*size)
+					if xMin, xMax, yMin, yMax, ok := r.collectPathEdges(p.Iter(), false); ok {
+						r.fillEdges(xMin, xMax, yMin, yMax, rule, func(y, xMin int, coverage []float32) {
+							copy(want[y*size+xMin:], coverage)
+						})
+					}
+
+					got := make([]float32, size*size)
+					r.fill(p.Iter(), rule, func(y, xMin int, coverage []float32) {
+						copy(got[y*size+xMin:], coverage)
+					})
+
+					for k := range want {
+						if !closeTo(got[k], want[k]) {
+							t.Errorf("path %d, CTM %d, rule %d, threshold %d: pixel (%d,%d) = %g, want %g",
+								i, j, rule, threshold, k%size, k/size, got[k], want[k])
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestCullZoom(t *testing.T) {
+	// A circle of radius 1e6, of which only a tiny part is visible.
+	const k = 0.5522847498
+	circle := (&path.Data{}).
+		MoveTo(vec.Vec2{X: 1, Y: 0}).
+		CubeTo(vec.Vec2{X: 1, Y: k}, vec.Vec2{X: k, Y: 1}, vec.Vec2{X: 0, Y: 1}).
+		CubeTo(vec.Vec2{X: -k, Y: 1}, vec.Vec2{X: -1, Y: k}, vec.Vec2{X: -1, Y: 0}).
+		CubeTo(vec.Vec2{X: -1, Y: -k}, vec.Vec2{X: -k, Y: -1}, vec.Vec2{X: 0, Y: -1}).
+		CubeTo(vec.Vec2{X: k, Y: -1}, vec.Vec2{X: 1, Y: -k}, vec.Vec2{X: 1, Y: 0}).
+		Close()
+
+	r := NewRasterizer(rect.Rect{URx: 10, URy: 10})
+	r.CTM = matrix.Scale(1e6, 1e6).Mul(matrix.Translate(-1e6+5, 5))
+
+	got := make([]float32, 100)
+	err := r.TryFillNonZero(circle.Iter(), func(y, xMin int, coverage []float32) {
+		copy(got[y*10+xMin:], coverage)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.edges) > 10 {
+		t.Errorf("%d edges, want at most 10", len(r.edges))
+	}
+	for i, c := range got {
+		want := float32(0)
+		if i%10 < 5 {
+			want = 1
+		}
+		if math.Abs(float64(c-want)) > 1e-2 {
+			t.Errorf("pixel (%d,%d) = %g, want %g", i%10, i/10, c, want)
+		}
+	}
+
+	// Bounds are not affected by culling.
+	b := r.FillBounds(circle.Iter())
+	if b.LLx > -1.9e6 || b.URx < 4 {
+		t.Errorf("fill bounds %v", b)
+	}
+}
+
 // BenchmarkRasterizeAll measures steady-state performance by reusing a single
 // Rasterizer across all test cases. This tests buffer reuse with varying clip sizes.
 func BenchmarkRasterizeAll(b *testing.B) {
@@ -332,6 +428,7 @@ func BenchmarkRasterizeAll(b *testing.B) {
 				r.MiterLimit = op.MiterLimit
 				r.Dash = op.Dash
 				r.DashPhase = op.DashPhase
+				r.StrokeAdjust = op.StrokeAdjust
 				r.Stroke(tc.Path.Iter(), emit)
 			}
 		}
@@ -0,0 +1,322 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package render
+
+import (
+	"context"
+	"errors"
+	"image/color"
+	"image/draw"
+
+	"seehuhn.de/go/geom/path"
+	"seehuhn.de/go/geom/rect"
+	"seehuhn.de/go/geom/vec"
+	"seehuhn.de/go/pdf"
+	"seehuhn.de/go/pdf/graphics"
+	pdfcolor "seehuhn.de/go/pdf/graphics/color"
+
+	"seehuhn.de/go/raster"
+	"seehuhn.de/go/raster/composite"
+)
+
+// painter executes the path construction, path painting and clipping
+// operators of a content stream. All other operators are interpreted by
+// the content stream reader, which maintains the graphics state.
+type painter struct {
+	ctx    context.Context
+	r      *raster.Rasterizer
+	fill   *composite.Compositor
+	stroke *composite.Compositor
+
+	// path is the current path, in user space.
+	path       path.Data
+	start      vec.Vec2 // start of the current subpath
+	current    vec.Vec2 // current point
+	hasCurrent bool     // whether there is a current point
+
+	// clip is set by W and W*; the clipping path is installed when the
+	// current path is painted.
+	clip     bool
+	clipRule raster.FillRule
+
+	args [6]float64
+}
+
+func newPainter(img draw.Image) *painter {
+	b := img.Bounds()
+	clip := rect.Rect{
+		LLx: float64(b.Min.X),
+		LLy: float64(b.Min.Y),
+		URx: float64(b.Max.X),
+		URy: float64(b.Max.Y),
+	}
+	return &painter{
+		ctx:    context.Background(),
+		r:      raster.NewRasterizer(clip),
+		fill:   composite.New(img, color.Black),
+		stroke: composite.New(img, color.Black),
+	}
+}
+
+// op executes the operator name with the given arguments. The graphics
+// state gs must already reflect the effect of the operator. Operators with
+// malformed arguments are ignored, as are unknown operators. The error, if
+// any, is as for paint.
+func (p *painter) op(gs *graphics.State, name string, args []pdf.Object) error {
+	switch name {
+	case "q":
+		p.r.Save()
+	case "Q":
+		p.r.Restore()
+
+	case "m":
+		if a, ok := p.numbers(args, 2); ok {
+			p.moveTo(vec.Vec2{X: a[0], Y: a[1]})
+		}
+	case "l":
+		if a, ok := p.numbers(args, 2); ok && p.hasCurrent {
+			p.lineTo(vec.Vec2{X: a[0], Y: a[1]})
+		}
+	case "c":
+		if a, ok := p.numbers(args, 6); ok && p.hasCurrent {
+			p.curveTo(vec.Vec2{X: a[0], Y: a[1]}, vec.Vec2{X: a[2], Y: a[3]}, vec.Vec2{X: a[4], Y: a[5]})
+		}
+	case "v":
+		if a, ok := p.numbers(args, 4); ok && p.hasCurrent {
+			p.curveTo(p.current, vec.Vec2{X: a[0], Y: a[1]}, vec.Vec2{X: a[2], Y: a[3]})
+		}
+	case "y":
+		if a, ok := p.numbers(args, 4); ok && p.hasCurrent {
+			end := vec.Vec2{X: a[2], Y: a[3]}
+			p.curveTo(vec.Vec2{X: a[0], Y: a[1]}, end, end)
+		}
+	case "h":
+		p.closePath()
+	case "re":
+		if a, ok := p.numbers(args, 4); ok {
+			x, y, w, h := a[0], a[1], a[2], a[3]
+			p.moveTo(vec.Vec2{X: x, Y: y})
+			p.lineTo(vec.Vec2{X: x + w, Y: y})
+			p.lineTo(vec.Vec2{X: x + w, Y: y + h})
+			p.lineTo(vec.Vec2{X: x, Y: y + h})
+			p.closePath()
+		}
+
+	case "W":
+		p.clip, p.clipRule = true, raster.NonZero
+	case "W*":
+		p.clip, p.clipRule = true, raster.EvenOdd
+
+	case "S":
+		return p.paint(gs, false, raster.NonZero, true)
+	case "s":
+		p.closePath()
+		return p.paint(gs, false, raster.NonZero, true)
+	case "f", "F":
+		return p.paint(gs, true, raster.NonZero, false)
+	case "f*":
+		return p.paint(gs, true, raster.EvenOdd, false)
+	case "B":
+		return p.paint(gs, true, raster.NonZero, true)
+	case "B*":
+		return p.paint(gs, true, raster.EvenOdd, true)
+	case "b":
+		p.closePath()
+		return p.paint(gs, true, raster.NonZero, true)
+	case "b*":
+		p.closePath()
+		return p.paint(gs, true, raster.EvenOdd, true)
+	case "n":
+		return p.paint(gs, false, raster.NonZero, false)
+	}
+	return nil
+}
+
+func (p *painter) moveTo(pt vec.Vec2) {
+	// A MoveTo directly following another replaces it.
+	if n := len(p.path.Cmds); n > 0 && p.path.Cmds[n-1] == path.CmdMoveTo {
+		p.path.Cmds = p.path.Cmds[:n-1]
+		p.path.Coords = p.path.Coords[:len(p.path.Coords)-1]
+	}
+	p.path.MoveTo(pt)
+	p.start, p.current, p.hasCurrent = pt, pt, true
+}
+
+func (p *painter) lineTo(pt vec.Vec2) {
+	p.reopen()
+	p.path.LineTo(pt)
+	p.current = pt
+}
+
+func (p *painter) curveTo(c1, c2, pt vec.Vec2) {
+	p.reopen()
+	p.path.CubeTo(c1, c2, pt)
+	p.current = pt
+}
+
+// reopen starts a new subpath at the current point, if the previous
+// subpath has been closed.
+func (p *painter) reopen() {
+	if n := len(p.path.Cmds); n > 0 && p.path.Cmds[n-1] == path.CmdClose {
+		p.path.MoveTo(p.current)
+	}
+}
+
+func (p *painter) closePath() {
+	if !p.hasCurrent {
+		return
+	}
+	p.path.Close()
+	p.current = p.start
+}
+
+// paint fills and/or strokes the current path, using the parameters from
+// gs, installs a pending clipping path, and then clears the current path.
+//
+// Paths which cannot be rendered, for example because of invalid
+// parameters in the content stream or because they exceed the limits of
+// the rasterizer, are skipped and the reason is returned. If only the
+// stroke parameters are invalid, the fill is still painted. A clipping
+// path which cannot be rendered is replaced by an empty one by
+// PushClipPathContext, so that the clipping region never becomes larger
+// than the content stream specifies.
+func (p *painter) paint(gs *graphics.State, fill bool, rule raster.FillRule, stroke bool) error {
+	r := p.r
+	r.CTM = gs.CTM
+
+	blend := blendMode(gs.BlendMode)
+	if fill {
+		p.fill.SetColor(deviceColor(gs.FillColor))
+		p.fill.Alpha = float32(gs.FillAlpha)
+		p.fill.Blend = blend
+	}
+	if stroke {
+		r.Width = gs.LineWidth
+		r.Cap = gs.LineCap
+		r.Join = gs.LineJoin
+		r.MiterLimit = gs.MiterLimit
+		r.Dash = dashPattern(gs.DashPattern)
+		r.DashPhase = gs.DashPhase
+		r.StrokeAdjust = gs.StrokeAdjustment
+
+		p.stroke.SetColor(deviceColor(gs.StrokeColor))
+		p.stroke.Alpha = float32(gs.StrokeAlpha)
+		p.stroke.Blend = blend
+	}
+
+	var err error
+	switch {
+	case fill && stroke:
+		err = r.Validate()
+		if err == nil {
+			err = r.FillStrokeContext(p.ctx, p.path.Iter(), rule, p.fill.Emit, p.stroke.Emit)
+		} else if fillErr := r.FillContext(p.ctx, p.path.Iter(), rule, p.fill.Emit); fillErr != nil {
+			err = fillErr
+		}
+	case fill:
+		err = r.FillContext(p.ctx, p.path.Iter(), rule, p.fill.Emit)
+	case stroke:
+		err = r.StrokeContext(p.ctx, p.path.Iter(), p.stroke.Emit)
+	}
+
+	if p.clip {
+		err = errors.Join(err, r.PushClipPathContext(p.ctx, p.path.Iter(), p.clipRule))
+		p.clip = false
+	}
+
+	p.path.Cmds = p.path.Cmds[:0]
+	p.path.Coords = p.path.Coords[:0]
+	p.hasCurrent = false
+	return err
+}
+
+// numbers converts the operator arguments to n numbers. The result is
+// only valid until the next call.
+func (p *painter) numbers(args []pdf.Object, n int) ([]float64, bool) {
+	if len(args) != n {
+		return nil, false
+	}
+	res := p.args[:n]
+	for i, arg := range args {
+		switch x := arg.(type) {
+		case pdf.Integer:
+			res[i] = float64(x)
+		case pdf.Real:
+			res[i] = float64(x)
+		case pdf.Number:
+			res[i] = float64(x)
+		default:
+			return nil, false
+		}
+	}
+	return res, true
+}
+
+// dashPattern converts a PDF dash array to the form used by the
+// rasterizer. An empty array, or one where all lengths are zero, selects
+// a solid line.
+func dashPattern(d []float64) []float64 {
+	for _, x := range d {
+		if x != 0 {
+			return d
+		}
+	}
+	return nil
+}
+
+// blendMode returns the first blend mode in m which is supported, or
+// BlendNormal if there is none, as described in section 11.6.3 of the
+// PDF specification.
+func blendMode(m graphics.BlendMode) composite.BlendMode {
+	for _, name := range m {
+		if mode, ok := composite.ParseBlendMode(string(name)); ok {
+			return mode
+		}
+	}
+	return composite.BlendNormal
+}
+
+// deviceColor converts a colour in one of the device colour spaces to an
+// sRGB colour, using the naive conversion formulas from section 10.4 of
+// the PDF specification. Colours in other colour spaces are mapped to
+// black.
+func deviceColor(c pdfcolor.Color) color.Color {
+	var r, g, b float64
+	switch c := c.(type) {
+	case pdfcolor.DeviceGray:
+		r, g, b = float64(c), float64(c), float64(c)
+	case pdfcolor.DeviceRGB:
+		r, g, b = c[0], c[1], c[2]
+	case pdfcolor.DeviceCMYK:
+		k := clamp01(c[3])
+		r = (1 - clamp01(c[0])) * (1 - k)
+		g = (1 - clamp01(c[1])) * (1 - k)
+		b = (1 - clamp01(c[2])) * (1 - k)
+	}
+	return color.RGBA64{R: to16(r), G: to16(g), B: to16(b), A: 0xffff}
+}
+
+func to16(x float64) uint16 {
+	return uint16(clamp01(x)*0xffff + 0.5)
+}
+
+func clamp01(x float64) float64 {
+	if !(x > 0) {
+		return 0
+	}
+	return min(x, 1)
+}
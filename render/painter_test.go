@@ -0,0 +1,302 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package render
+
+import (
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"slices"
+	"testing"
+
+	"seehuhn.de/go/geom/matrix"
+	"seehuhn.de/go/geom/path"
+	"seehuhn.de/go/geom/vec"
+	"seehuhn.de/go/pdf"
+	"seehuhn.de/go/pdf/graphics"
+	pdfcolor "seehuhn.de/go/pdf/graphics/color"
+
+	"seehuhn.de/go/raster"
+)
+
+// args converts numbers to operator arguments.
+func args(xs ...float64) []pdf.Object {
+	res := make([]pdf.Object, len(xs))
+	for i, x := range xs {
+		res[i] = pdf.Real(x)
+	}
+	return res
+}
+
+func newTestImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	return img
+}
+
+func TestPainterPath(t *testing.T) {
+	img := newTestImage(10, 10)
+	p := newPainter(img)
+	gs := graphics.NewState()
+
+	p.op(&gs, "l", args(1, 1)) // no current point
+	p.op(&gs, "m", args(9, 9))
+	p.op(&gs, "m", args(0, 0)) // replaces the previous MoveTo
+	p.op(&gs, "v", args(1, 1, 2, 0))
+	p.op(&gs, "y", args(3, 1, 4, 0))
+	p.op(&gs, "h", nil)
+	p.op(&gs, "l", []pdf.Object{pdf.Integer(5), pdf.Name("x")}) // malformed
+	p.op(&gs, "l", []pdf.Object{pdf.Integer(5), pdf.Integer(5)})
+
+	want := (&path.Data{}).
+		MoveTo(vec.Vec2{X: 0, Y: 0}).
+		CubeTo(vec.Vec2{X: 0, Y: 0}, vec.Vec2{X: 1, Y: 1}, vec.Vec2{X: 2, Y: 0}).
+		CubeTo(vec.Vec2{X: 3, Y: 1}, vec.Vec2{X: 4, Y: 0}, vec.Vec2{X: 4, Y: 0}).
+		Close().
+		MoveTo(vec.Vec2{X: 0, Y: 0}).
+		LineTo(vec.Vec2{X: 5, Y: 5})
+	if !slices.Equal(p.path.Cmds, want.Cmds) || !slices.Equal(p.path.Coords, want.Coords) {
+		t.Errorf("got path %v %v, want %v %v", p.path.Cmds, p.path.Coords, want.Cmds, want.Coords)
+	}
+
+	p.op(&gs, "n", nil)
+	if len(p.path.Cmds) != 0 || p.hasCurrent {
+		t.Error("n did not clear the current path")
+	}
+}
+
+func TestPainterFill(t *testing.T) {
+	img := newTestImage(20, 20)
+	p := newPainter(img)
+	gs := graphics.NewState()
+
+	gs.FillColor = pdfcolor.DeviceRGB{1, 0, 0}
+	p.op(&gs, "re", args(5, 5, 10, 10))
+	p.op(&gs, "f", nil)
+
+	// Two nested squares: the even-odd rule leaves a hole where they
+	// overlap.
+	gs.FillColor = pdfcolor.DeviceGray(0)
+	p.op(&gs, "re", args(0, 0, 4, 4))
+	p.op(&gs, "re", args(1, 1, 2, 2))
+	p.op(&gs, "f*", nil)
+
+	red := color.RGBA{255, 0, 0, 255}
+	black := color.RGBA{0, 0, 0, 255}
+	white := color.RGBA{255, 255, 255, 255}
+	cases := []struct {
+		x, y int
+		want color.RGBA
+	}{
+		{10, 10, red},
+		{5, 14, red},
+		{15, 10, white},
+		{0, 0, black},
+		{3, 0, black},
+		{1, 1, white},
+		{2, 2, white},
+	}
+	for _, c := range cases {
+		if got := img.RGBAAt(c.x, c.y); got != c.want {
+			t.Errorf("pixel (%d,%d) = %v, want %v", c.x, c.y, got, c.want)
+		}
+	}
+}
+
+func TestPainterStroke(t *testing.T) {
+	img := newTestImage(20, 20)
+	p := newPainter(img)
+	gs := graphics.NewState()
+
+	gs.CTM = matrix.Scale(2, 2)
+	gs.LineWidth = 1
+	gs.StrokeColor = pdfcolor.DeviceCMYK{0, 0, 0, 1}
+	gs.FillColor = pdfcolor.DeviceRGB{0, 0, 1}
+	p.op(&gs, "re", args(2, 2, 5, 5))
+	p.op(&gs, "B", nil)
+
+	black := color.RGBA{0, 0, 0, 255}
+	blue := color.RGBA{0, 0, 255, 255}
+	white := color.RGBA{255, 255, 255, 255}
+	cases := []struct {
+		x, y int
+		want color.RGBA
+	}{
+		{3, 8, black},
+		{4, 8, black},
+		{10, 3, black},
+		{10, 10, blue},
+		{1, 1, white},
+		{15, 15, white},
+	}
+	for _, c := range cases {
+		if got := img.RGBAAt(c.x, c.y); got != c.want {
+			t.Errorf("pixel (%d,%d) = %v, want %v", c.x, c.y, got, c.want)
+		}
+	}
+}
+
+func TestPainterClip(t *testing.T) {
+	img := newTestImage(20, 20)
+	p := newPainter(img)
+	gs := graphics.NewState()
+
+	p.op(&gs, "q", nil)
+	p.op(&gs, "re", args(0, 0, 10, 20))
+	p.op(&gs, "W", nil)
+	p.op(&gs, "n", nil)
+	p.op(&gs, "q", nil)
+	p.op(&gs, "re", args(0, 0, 20, 10))
+	p.op(&gs, "W", nil)
+	p.op(&gs, "n", nil)
+	if d := p.r.ClipDepth(); d != 2 {
+		t.Fatalf("clip depth %d, want 2", d)
+	}
+	p.op(&gs, "Q", nil)
+	p.op(&gs, "re", args(0, 0, 20, 20))
+	p.op(&gs, "f", nil)
+	p.op(&gs, "Q", nil)
+	if d := p.r.ClipDepth(); d != 0 {
+		t.Fatalf("clip depth %d after Q, want 0", d)
+	}
+
+	for _, y := range []int{5, 15} {
+		for _, x := range []int{5, 15} {
+			want := uint8(255)
+			if x < 10 {
+				want = 0
+			}
+			if got := img.RGBAAt(x, y).R; got != want {
+				t.Errorf("pixel (%d,%d) has red %d, want %d", x, y, got, want)
+			}
+		}
+	}
+}
+
+func TestDeviceColor(t *testing.T) {
+	cases := []struct {
+		in   pdfcolor.Color
+		want color.RGBA64
+	}{
+		{pdfcolor.DeviceGray(1), color.RGBA64{0xffff, 0xffff, 0xffff, 0xffff}},
+		{pdfcolor.DeviceGray(2), color.RGBA64{0xffff, 0xffff, 0xffff, 0xffff}},
+		{pdfcolor.DeviceRGB{1, 0, 0.5}, color.RGBA64{0xffff, 0, 0x8000, 0xffff}},
+		{pdfcolor.DeviceCMYK{1, 0, 0, 0}, color.RGBA64{0, 0xffff, 0xffff, 0xffff}},
+		{pdfcolor.DeviceCMYK{0, 0, 0, 0.5}, color.RGBA64{0x8000, 0x8000, 0x8000, 0xffff}},
+		{nil, color.RGBA64{0, 0, 0, 0xffff}},
+	}
+	for _, c := range cases {
+		if got := deviceColor(c.in); got != c.want {
+			t.Errorf("deviceColor(%v) = %v, want %v", c.in, got, c.want)
+		}
+	}
+}
+
+func TestPainterLimits(t *testing.T) {
+	img := newTestImage(20, 20)
+	p := newPainter(img)
+	gs := graphics.NewState()
+
+	// A path exceeding the limits is skipped, later paths are drawn.
+	p.r.Limits.MaxSegments = 10
+	p.op(&gs, "m", args(0, 0))
+	for i := range 20 {
+		p.op(&gs, "l", args(float64(i), 20))
+	}
+	if err := p.op(&gs, "f", nil); err == nil {
+		t.Error("missing error for skipped path")
+	}
+	p.op(&gs, "re", args(10, 10, 5, 5))
+	p.op(&gs, "f", nil)
+
+	if c := img.RGBAAt(2, 15); c != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("pixel (2,15) = %v, want white", c)
+	}
+	if c := img.RGBAAt(12, 12); c != (color.RGBA{0, 0, 0, 255}) {
+		t.Errorf("pixel (12,12) = %v, want black", c)
+	}
+
+	// A clipping path exceeding the limits clips everything.
+	p.op(&gs, "q", nil)
+	p.op(&gs, "m", args(0, 0))
+	for i := range 20 {
+		p.op(&gs, "l", args(20, float64(i)))
+	}
+	p.op(&gs, "W", nil)
+	p.op(&gs, "n", nil)
+	if d := p.r.ClipDepth(); d != 1 {
+		t.Errorf("clip depth %d, want 1", d)
+	}
+	p.op(&gs, "re", args(0, 0, 5, 5))
+	p.op(&gs, "f", nil)
+	p.op(&gs, "Q", nil)
+	if c := img.RGBAAt(2, 2); c != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("pixel (2,2) = %v, want white", c)
+	}
+
+	// Nothing is drawn once the context is cancelled.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	p.ctx = ctx
+	p.op(&gs, "re", args(0, 0, 5, 5))
+	p.op(&gs, "f", nil)
+	if c := img.RGBAAt(2, 2); c != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("pixel (2,2) = %v, want white", c)
+	}
+}
+
+func TestPainterInvalidStroke(t *testing.T) {
+	img := newTestImage(20, 20)
+	p := newPainter(img)
+	gs := graphics.NewState()
+
+	// With an invalid line width, the fill is still painted.
+	gs.LineWidth = -1
+	gs.FillColor = pdfcolor.DeviceRGB{0, 0, 1}
+	p.op(&gs, "re", args(5, 5, 10, 10))
+	err := p.op(&gs, "B", nil)
+	var paramErr *raster.ParameterError
+	if !errors.As(err, &paramErr) {
+		t.Errorf("got error %v, want a ParameterError", err)
+	}
+	if c := img.RGBAAt(10, 10); c != (color.RGBA{0, 0, 255, 255}) {
+		t.Errorf("pixel (10,10) = %v, want blue", c)
+	}
+}
+
+func TestPainterBlendMode(t *testing.T) {
+	img := newTestImage(20, 20)
+	p := newPainter(img)
+	gs := graphics.NewState()
+
+	gs.FillColor = pdfcolor.DeviceRGB{1, 1, 0}
+	p.op(&gs, "re", args(0, 0, 20, 20))
+	p.op(&gs, "f", nil)
+
+	// The first supported blend mode is used.
+	gs.BlendMode = graphics.BlendMode{"Unknown", graphics.BlendModeMultiply}
+	gs.FillColor = pdfcolor.DeviceRGB{0, 1, 1}
+	p.op(&gs, "re", args(5, 5, 10, 10))
+	if err := p.op(&gs, "f", nil); err != nil {
+		t.Fatal(err)
+	}
+	if c := img.RGBAAt(10, 10); c != (color.RGBA{0, 255, 0, 255}) {
+		t.Errorf("pixel (10,10) = %v, want green", c)
+	}
+}
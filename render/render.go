@@ -0,0 +1,137 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package render draws the vector graphics of PDF pages into images.
+//
+// The content stream of a page is read using seehuhn.de/go/pdf, and the
+// path construction (m, l, c, v, y, h, re), path painting (S, s, f, F, f*,
+// B, B*, b, b*, n) and clipping (W, W*) operators are executed using a
+// raster.Rasterizer. The graphics state operators q, Q, cm, w, J, j, M, d
+// and gs are honoured, as are the colour operators for the DeviceGray,
+// DeviceRGB and DeviceCMYK colour spaces; colours in other colour spaces
+// are drawn in black. Of the transparency parameters set by gs, the
+// constant alpha values CA and ca and the blend mode BM are used; soft
+// masks (SMask) are ignored. Text, images, shadings and XObjects are not
+// drawn.
+//
+//	img, err := render.Page(r, 0, 150)
+package render
+
+import (
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"log/slog"
+	"math"
+
+	"seehuhn.de/go/geom/matrix"
+	"seehuhn.de/go/pdf"
+	"seehuhn.de/go/pdf/pagetree"
+	"seehuhn.de/go/pdf/reader"
+)
+
+// errResolution is returned by Page for a non-positive or non-finite
+// resolution.
+var errResolution = errors.New("render: invalid resolution")
+
+// Page renders the page with index pageNo (starting from 0) of the PDF
+// document r, at a resolution of dpi pixels per inch. The visible region
+// of the page is given by the CropBox, or by the MediaBox if no CropBox
+// is present, and the Rotate entry of the page is taken into account.
+// The page is drawn onto a white background.
+func Page(r pdf.Getter, pageNo int, dpi float64) (*image.RGBA, error) {
+	return PageContext(context.Background(), r, pageNo, dpi)
+}
+
+// PageContext is like Page, but rendering stops with ctx.Err() once ctx
+// is cancelled. Paths which exceed the default limits of the rasterizer
+// (see raster.Limits) are skipped, so that hostile input cannot use
+// excessive amounts of time or memory. Skipped paths, and paths with
+// invalid parameters, are reported through log/slog at level Warn.
+func PageContext(ctx context.Context, r pdf.Getter, pageNo int, dpi float64) (*image.RGBA, error) {
+	if !(dpi > 0) || math.IsInf(dpi, 1) {
+		return nil, errResolution
+	}
+
+	_, pageDict, err := pagetree.GetPage(r, pageNo)
+	if err != nil {
+		return nil, err
+	}
+	box, err := pdf.GetRectangle(r, pageDict["CropBox"])
+	if err != nil || box == nil {
+		box, err = pdf.GetRectangle(r, pageDict["MediaBox"])
+		if err != nil {
+			return nil, err
+		} else if box == nil {
+			return nil, errors.New("render: missing MediaBox")
+		}
+	}
+	rotate, err := pdf.GetInteger(r, pageDict["Rotate"])
+	if err != nil {
+		return nil, err
+	}
+
+	ctm, width, height := pageTransform(box, int(rotate), dpi/72)
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+
+	p := newPainter(img)
+	p.ctx = ctx
+	rd := reader.New(r)
+	rd.UnknownOp = func(op string, args []pdf.Object) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := p.op(rd.State.GState, op, args); err != nil && ctx.Err() == nil {
+			slog.Warn("render: path skipped", "op", op, "error", err)
+		}
+		return nil
+	}
+	err = rd.ParsePage(pageDict, ctm)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	} else if err != nil {
+		return nil, err
+	}
+	return img, nil
+}
+
+// pageTransform returns the matrix which maps PDF default user space to
+// the pixel coordinates of the rendered page, together with the size of
+// the image in pixels. Rotation is clockwise, in multiples of 90 degrees.
+func pageTransform(box *pdf.Rectangle, rotate int, scale float64) (matrix.Matrix, int, int) {
+	w := pixels(box.Dx() * scale)
+	h := pixels(box.Dy() * scale)
+
+	switch ((rotate%360 + 360) % 360) / 90 {
+	case 1:
+		return matrix.Matrix{0, scale, scale, 0, -box.LLy * scale, -box.LLx * scale}, h, w
+	case 2:
+		return matrix.Matrix{-scale, 0, 0, scale, box.URx * scale, -box.LLy * scale}, w, h
+	case 3:
+		return matrix.Matrix{0, -scale, -scale, 0, box.URy * scale, box.URx * scale}, h, w
+	default:
+		return matrix.Matrix{scale, 0, 0, -scale, -box.LLx * scale, box.URy * scale}, w, h
+	}
+}
+
+// pixels returns the number of pixels needed to cover a length of x
+// pixels. Rounding errors in the computation of x are ignored.
+func pixels(x float64) int {
+	return max(int(math.Ceil(x-1e-6)), 1)
+}
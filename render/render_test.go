@@ -0,0 +1,115 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package render
+
+import (
+	"bytes"
+	"image/color"
+	"math"
+	"testing"
+
+	"seehuhn.de/go/geom/matrix"
+	"seehuhn.de/go/pdf"
+	"seehuhn.de/go/pdf/document"
+	pdfcolor "seehuhn.de/go/pdf/graphics/color"
+)
+
+func TestPageTransform(t *testing.T) {
+	box := &pdf.Rectangle{LLx: 10, LLy: 20, URx: 110, URy: 70}
+
+	type point struct{ x, y, X, Y float64 }
+	cases := []struct {
+		rotate int
+		w, h   int
+		points []point
+	}{
+		{0, 200, 100, []point{{10, 70, 0, 0}, {110, 20, 200, 100}}},
+		{90, 100, 200, []point{{10, 20, 0, 0}, {10, 70, 100, 0}, {110, 70, 100, 200}}},
+		{180, 200, 100, []point{{110, 20, 0, 0}, {10, 70, 200, 100}}},
+		{270, 100, 200, []point{{110, 70, 0, 0}, {10, 70, 0, 200}, {110, 20, 100, 0}}},
+		{-90, 100, 200, []point{{110, 70, 0, 0}}},
+	}
+	for _, c := range cases {
+		M, w, h := pageTransform(box, c.rotate, 2)
+		if w != c.w || h != c.h {
+			t.Errorf("rotate %d: size %dx%d, want %dx%d", c.rotate, w, h, c.w, c.h)
+		}
+		for _, pt := range c.points {
+			X, Y := M.Apply(pt.x, pt.y)
+			if math.Abs(X-pt.X) > 1e-9 || math.Abs(Y-pt.Y) > 1e-9 {
+				t.Errorf("rotate %d: (%g,%g) maps to (%g,%g), want (%g,%g)",
+					c.rotate, pt.x, pt.y, X, Y, pt.X, pt.Y)
+			}
+		}
+	}
+}
+
+func TestPage(t *testing.T) {
+	buf := &bytes.Buffer{}
+	page, err := document.WriteSinglePage(buf, &pdf.Rectangle{URx: 72, URy: 36}, pdf.V1_7, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page.SetFillColor(pdfcolor.DeviceRGB{0, 0, 1})
+	page.Rectangle(0, 0, 36, 36)
+	page.Fill()
+	page.PushGraphicsState()
+	page.Transform(matrix.Translate(36, 0))
+	page.SetStrokeColor(pdfcolor.DeviceGray(0))
+	page.SetLineWidth(4)
+	page.MoveTo(0, 18)
+	page.LineTo(36, 18)
+	page.Stroke()
+	page.PopGraphicsState()
+	if err := page.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := pdf.NewReader(bytes.NewReader(buf.Bytes()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	img, err := Page(r, 0, 144)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 144 || b.Dy() != 72 {
+		t.Fatalf("image size %dx%d, want 144x72", b.Dx(), b.Dy())
+	}
+
+	cases := []struct {
+		x, y int
+		want color.RGBA
+	}{
+		{10, 10, color.RGBA{0, 0, 255, 255}},
+		{70, 60, color.RGBA{0, 0, 255, 255}},
+		{100, 36, color.RGBA{0, 0, 0, 255}},
+		{100, 10, color.RGBA{255, 255, 255, 255}},
+		{100, 60, color.RGBA{255, 255, 255, 255}},
+	}
+	for _, c := range cases {
+		if got := img.RGBAAt(c.x, c.y); got != c.want {
+			t.Errorf("pixel (%d,%d) = %v, want %v", c.x, c.y, got, c.want)
+		}
+	}
+
+	if _, err := Page(r, 0, 0); err == nil {
+		t.Error("missing error for zero resolution")
+	}
+}
@@ -0,0 +1,170 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package raster
+
+import "seehuhn.de/go/geom/path"
+
+// Span is a horizontal run of pixels with constant coverage.
+type Span struct {
+	// X0 and X1 are the first and last+1 pixel of the run.
+	X0, X1 int
+
+	// Coverage is the coverage of all pixels in the run, in (0, 1].
+	Coverage float32
+}
+
+// FillSpans fills the path using the given fill rule, and reports the
+// coverage of each row as a list of spans. Runs of pixels with identical
+// coverage, such as the interior of a shape, are combined into a single
+// span, while anti-aliased edge pixels typically form spans of length 1.
+// Pixels with zero coverage are omitted.
+//
+// The spans are generated directly from the cells of the edges (see
+// Approach C in the specification), so the cost per row is proportional
+// to the number of edge pixels, not to the width of the row. If a clip
+// path is active, the coverage is computed for every pixel instead, since
+// the clip mask varies from pixel to pixel.
+//
+// The spans of a row are sorted by X0 and do not overlap. The emit
+// callback is called at most once per row, in order of increasing y; its
+// slice argument is valid only during the call.
+func (r *Rasterizer) FillSpans(p path.Path, rule FillRule, emit func(y int, spans []Span)) {
+	xMin, xMax, yMin, yMax, ok := r.collectPathEdges(p, true)
+	if !ok {
+		return
+	}
+	r.fillEdgesSpans(xMin, xMax, yMin, yMax, rule, emit)
+}
+
+// StrokeSpans strokes the path and reports the coverage as spans, as
+// described for FillSpans.
+func (r *Rasterizer) StrokeSpans(p path.Path, emit func(y int, spans []Span)) {
+	r.buildStrokeOutlines(p)
+	if len(r.strokeOffsets) == 0 {
+		return
+	}
+	xMin, xMax, yMin, yMax, ok := r.collectStrokeEdges(true)
+	if !ok {
+		return
+	}
+	r.fillEdgesSpans(xMin, xMax, yMin, yMax, NonZero, emit)
+}
+
+// spanBytes is the size of a Span in bytes, for Limits.MaxBufferBytes.
+const spanBytes = 24
+
+// fillEdgesSpans rasterises the collected edges and emits spans.
+//
+// A row has at most one span per pixel. If limits are active, the cells
+// are only used if their estimated size fits into Limits.MaxBufferBytes;
+// otherwise the coverage is computed as for FillNonZero and FillEvenOdd.
+func (r *Rasterizer) fillEdgesSpans(xMin, xMax, yMin, yMax int, rule FillRule, emit func(y int, spans []Span)) {
+	if r.aborted() {
+		return
+	}
+	if !r.bufferFits(spanBytes * (xMax - xMin)) {
+		r.abort("MaxBufferBytes", r.limits.MaxBufferBytes)
+		return
+	}
+
+	if r.clipDepth > 0 || !r.bufferFits(int(cellBytes*r.estimateCells(yMin, yMax))) {
+		r.fillEdges(xMin, xMax, yMin, yMax, rule, func(y, xMin int, coverage []float32) {
+			r.spans = appendSpans(r.spans[:0], xMin, coverage)
+			emit(y, r.spans)
+		})
+		return
+	}
+
+	if !r.collectCells(xMin, xMax, yMin, yMax) {
+		return
+	}
+
+	coverage := nonZeroCoverage
+	if rule == EvenOdd {
+		coverage = evenOddCoverage
+	}
+
+	cells := r.cells
+	for len(cells) > 0 && !r.canceled() {
+		y := cells[0].y
+		n := 1
+		for n < len(cells) && cells[n].y == y {
+			n++
+		}
+		row := cells[:n]
+		cells = cells[n:]
+
+		// The cells are summed as in fillSparsePath, so that the coverage
+		// values are the same.
+		spans := r.spans[:0]
+		var accum float32
+		for k := 0; k < len(row); {
+			x := row[k].x
+			var cover, area float32
+			for k < len(row) && row[k].x == x {
+				cover += row[k].cover
+				area += row[k].area
+				k++
+			}
+			spans = appendSpan(spans, x, x+1, coverage(accum+area))
+			accum += cover
+
+			// the run up to the next cell, or to the edge of the bounding box
+			next := xMax
+			if k < len(row) {
+				next = row[k].x
+			}
+			if x+1 < next {
+				spans = appendSpan(spans, x+1, next, coverage(accum))
+			}
+		}
+		r.spans = spans
+
+		if len(spans) > 0 {
+			emit(y, spans)
+		}
+	}
+}
+
+// appendSpans appends the runs of equal, non-zero values in the row
+// coverage, which starts at pixel xMin, to spans.
+func appendSpans(spans []Span, xMin int, coverage []float32) []Span {
+	for i := 0; i < len(coverage); {
+		c := coverage[i]
+		j := i + 1
+		for j < len(coverage) && coverage[j] == c {
+			j++
+		}
+		spans = appendSpan(spans, xMin+i, xMin+j, c)
+		i = j
+	}
+	return spans
+}
+
+// appendSpan appends the run of pixels x0, …, x1-1 with coverage c to spans,
+// extending the last span if possible. Runs with zero coverage are
+// dropped.
+func appendSpan(spans []Span, x0, x1 int, c float32) []Span {
+	if c == 0 {
+		return spans
+	}
+	if n := len(spans); n > 0 && spans[n-1].X1 == x0 && spans[n-1].Coverage == c {
+		spans[n-1].X1 = x1
+		return spans
+	}
+	return append(spans, Span{X0: x0, X1: x1, Coverage: c})
+}
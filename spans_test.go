@@ -0,0 +1,174 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package raster
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"seehuhn.de/go/geom/path"
+	"seehuhn.de/go/geom/rect"
+	"seehuhn.de/go/geom/vec"
+)
+
+func TestFillSpansRectangle(t *testing.T) {
+	r := NewRasterizer(rect.Rect{URx: 5000, URy: 10})
+	rows := 0
+	r.FillSpans(rectPath(10.5, 2, 4010.5, 8).Iter(), NonZero, func(y int, spans []Span) {
+		rows++
+		want := []Span{{10, 11, 0.5}, {11, 4010, 1}, {4010, 4011, 0.5}}
+		if !slices.Equal(spans, want) {
+			t.Errorf("row %d: got %v, want %v", y, spans, want)
+		}
+	})
+	if rows != 6 {
+		t.Errorf("got %d rows, want 6", rows)
+	}
+
+	// The interior is not computed pixel by pixel.
+	if cap(r.cover) != 0 || cap(r.area) != 0 {
+		t.Errorf("dense coverage buffers used: %d, %d", cap(r.cover), cap(r.area))
+	}
+}
+
+func TestFillSpansClip(t *testing.T) {
+	const size = 40
+	r := NewRasterizer(rect.Rect{URx: size, URy: size})
+	r.PushClipPath(starPath(5, size).Iter(), NonZero)
+	p := rectPath(3.5, 4.25, 36, 30)
+
+	want := make([]float32, size*size)
+	r.fill(p.Iter(), NonZero, func(y, xMin int, coverage []float32) {
+		copy(want[y*size+xMin:], coverage)
+	})
+
+	got := make([]float32, size*size)
+	r.FillSpans(p.Iter(), NonZero, func(y int, spans []Span) {
+		checkSpans(t, y, spans)
+		for _, s := range spans {
+			for x := s.X0; x < s.X1; x++ {
+				got[y*size+x] = s.Coverage
+			}
+		}
+	})
+	if !slices.Equal(got, want) {
+		t.Error("spans differ from coverage rows")
+	}
+}
+
+func TestFillSpansMatchesFill(t *testing.T) {
+	const size = 100
+	star := starPath(13, size)
+	line := (&path.Data{}).MoveTo(vec.Vec2{X: 5, Y: 50}).LineTo(vec.Vec2{X: 95, Y: 60})
+
+	for _, rule := range []FillRule{NonZero, EvenOdd} {
+		r := NewRasterizer(rect.Rect{URx: size, URy: size})
+		want := make([]float32, size*size)
+		r.fill(star.Iter(), rule, func(y, xMin int, coverage []float32) {
+			copy(want[y*size+xMin:], coverage)
+		})
+
+		got := make([]float32, size*size)
+		lastY := -1
+		r.FillSpans(star.Iter(), rule, func(y int, spans []Span) {
+			if y <= lastY {
+				t.Errorf("row %d emitted after row %d", y, lastY)
+			}
+			lastY = y
+			checkSpans(t, y, spans)
+			for _, s := range spans {
+				for x := s.X0; x < s.X1; x++ {
+					got[y*size+x] = s.Coverage
+				}
+			}
+		})
+
+		if !slices.Equal(got, want) {
+			t.Errorf("rule %d: spans differ from coverage rows", rule)
+		}
+	}
+
+	r := NewRasterizer(rect.Rect{URx: size, URy: size})
+	r.Width = 3
+	r.StrokeSpans(line.Iter(), func(y int, spans []Span) {
+		checkSpans(t, y, spans)
+	})
+}
+
+// checkSpans verifies that spans are non-empty, sorted, non-overlapping
+// and have non-zero coverage.
+func checkSpans(t *testing.T, y int, spans []Span) {
+	t.Helper()
+	if len(spans) == 0 {
+		t.Errorf("row %d: no spans", y)
+	}
+	for i, s := range spans {
+		if s.X1 <= s.X0 || s.Coverage <= 0 || s.Coverage > 1 {
+			t.Errorf("row %d: invalid span %v", y, s)
+		}
+		if i > 0 && s.X0 < spans[i-1].X1 {
+			t.Errorf("row %d: span %v overlaps %v", y, s, spans[i-1])
+		}
+	}
+}
+
+func TestFillSpansLimits(t *testing.T) {
+	const size = 1000
+	ctx := context.Background()
+	p := rectPath(0.5, 0.5, size-0.5, size-0.5)
+
+	r := NewRasterizer(rect.Rect{URx: size, URy: size})
+	var want []Span
+	r.FillSpans(p.Iter(), NonZero, func(y int, spans []Span) {
+		if y == size/2 {
+			want = slices.Clone(spans)
+		}
+	})
+
+	// The cells don't fit, so the spans are computed from coverage rows.
+	r = NewRasterizer(rect.Rect{URx: size, URy: size})
+	r.Limits = Limits{MaxBufferBytes: 30000}
+	var got []Span
+	err := r.runLimited(ctx, p.Iter(), false, func() {
+		r.FillSpans(p.Iter(), NonZero, func(y int, spans []Span) {
+			if y == size/2 {
+				got = slices.Clone(spans)
+			}
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cap(r.cells) != 0 {
+		t.Error("sparse cells were allocated")
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// The spans of a row don't fit.
+	r.Limits = Limits{MaxBufferBytes: 10000}
+	err = r.runLimited(ctx, p.Iter(), false, func() {
+		r.FillSpans(p.Iter(), NonZero, func(y int, spans []Span) {
+			t.Error("unexpected spans")
+		})
+	})
+	if limitOf(err) != "MaxBufferBytes" {
+		t.Errorf("got error %v, want MaxBufferBytes", err)
+	}
+}
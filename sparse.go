@@ -0,0 +1,184 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package raster
+
+import (
+	"cmp"
+	"math"
+	"slices"
+)
+
+// cell holds the cover and area contribution of one edge to one pixel,
+// for Approach C.
+type cell struct {
+	x, y        int
+	cover, area float32
+}
+
+// estimateCells returns an estimate for the number of cells generated by
+// the edges within the scanlines yMin ≤ y < yMax.
+func (r *Rasterizer) estimateCells(yMin, yMax int) float64 {
+	fyMin, fyMax := float64(yMin), float64(yMax)
+	n := 0.0
+	for i := range r.edges {
+		e := &r.edges[i]
+		top := max(min(e.y0, e.y1), fyMin)
+		bot := min(max(e.y0, e.y1), fyMax)
+		if bot <= top {
+			continue
+		}
+		rows := math.Ceil(bot) - math.Floor(top)
+		cols := math.Abs(e.dxdy) * (bot - top)
+		n += rows + cols + 1
+	}
+	return n
+}
+
+// cellBytes is the size of a cell in bytes, for Limits.MaxBufferBytes.
+const cellBytes = 24
+
+// useSparse reports whether Approach C should be used for the scanlines
+// yMin ≤ y < yMax of a bounding box with size pixels: the cells must be
+// few compared to the pixels, and fit into the buffer limit.
+func (r *Rasterizer) useSparse(yMin, yMax, size int) bool {
+	n := r.estimateCells(yMin, yMax)
+	return n < r.sparseCellRatio*float64(size) && r.bufferFits(int(cellBytes*n))
+}
+
+// fillSparsePath rasterises using sparse cells (Approach C). Only the
+// pixels touched by edges are recorded; between these, the coverage is
+// constant along a scanline and is filled in directly. This is used for
+// large bounding boxes where few pixels lie on edges, for example for
+// thin diagonal strokes.
+//
+// The cells of each pixel are summed in the same order as in Approach B,
+// so the results are bit-identical.
+func (r *Rasterizer) fillSparsePath(xMin, xMax, yMin, yMax int, rule FillRule, emit func(y, xMin int, coverage []float32)) {
+	if !r.collectCells(xMin, xMax, yMin, yMax) {
+		return
+	}
+
+	coverage := nonZeroCoverage
+	if rule == EvenOdd {
+		coverage = evenOddCoverage
+	}
+
+	cells := r.cells
+	for len(cells) > 0 && !r.canceled() {
+		y := cells[0].y
+		n := 1
+		for n < len(cells) && cells[n].y == y {
+			n++
+		}
+		row := cells[:n]
+		cells = cells[n:]
+
+		// If the cover does not return to zero, the coverage extends to
+		// the right edge of the bounding box. The cover is summed per
+		// pixel first, to get the same rounding as below.
+		var total float32
+		for k := 0; k < len(row); {
+			var cover float32
+			for x := row[k].x; k < len(row) && row[k].x == x; k++ {
+				cover += row[k].cover
+			}
+			total += cover
+		}
+		x0 := row[0].x
+		x1 := row[n-1].x + 1
+		if coverage(total) != 0 {
+			x1 = xMax
+		}
+		width := x1 - x0
+		buf := slices.Grow(r.cover[:0], width)[:width]
+		r.cover = buf
+
+		var accum float32
+		i := 0
+		for k := 0; k < len(row); {
+			// fill the run up to the next cell
+			x := row[k].x - x0
+			if i < x {
+				fillConst(buf[i:x], coverage(accum))
+				i = x
+			}
+
+			var cover, area float32
+			for k < len(row) && row[k].x-x0 == x {
+				cover += row[k].cover
+				area += row[k].area
+				k++
+			}
+			buf[i] = coverage(accum + area)
+			accum += cover
+			i++
+		}
+		fillConst(buf[i:], coverage(accum))
+
+		r.emitRow(y, x0, buf, emit)
+	}
+}
+
+// collectCells computes the cells of all edges within the given bounding
+// box and sorts them by row and column. The result is false if the
+// operation was cancelled, or if the cells exceed Limits.MaxBufferBytes.
+func (r *Rasterizer) collectCells(xMin, xMax, yMin, yMax int) bool {
+	r.sortEdges()
+
+	r.cells = r.cells[:0]
+	for i := range r.edges {
+		if r.canceled() {
+			return false
+		}
+		e := &r.edges[i]
+		y0 := max(int(math.Floor(min(e.y0, e.y1))), yMin)
+		y1 := min(int(math.Ceil(max(e.y0, e.y1))), yMax)
+		for y := y0; y < y1; y++ {
+			accumulateEdge(e, y, xMin, xMax, cellSink{r, y})
+		}
+		if !r.bufferFits(cellBytes * len(r.cells)) {
+			r.abort("MaxBufferBytes", r.limits.MaxBufferBytes)
+			return false
+		}
+	}
+
+	// A stable sort keeps the cells of each pixel in edge order.
+	slices.SortStableFunc(r.cells, func(a, b cell) int {
+		if c := cmp.Compare(a.y, b.y); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.x, b.x)
+	})
+	return true
+}
+
+// cellSink records contributions to scanline y as cells, for Approach C.
+type cellSink struct {
+	r *Rasterizer
+	y int
+}
+
+func (s cellSink) add(x int, cover, area float32) {
+	s.r.cells = append(s.r.cells, cell{x: x, y: s.y, cover: cover, area: area})
+}
+
+// fillConst sets all elements of buf to v.
+func fillConst(buf []float32, v float32) {
+	for i := range buf {
+		buf[i] = v
+	}
+}
@@ -0,0 +1,106 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package raster
+
+import (
+	"math"
+	"testing"
+
+	"seehuhn.de/go/geom/path"
+	"seehuhn.de/go/geom/rect"
+	"seehuhn.de/go/geom/vec"
+)
+
+func TestSparseBitIdentical(t *testing.T) {
+	const size = 120
+	star := starPath(19, size)
+
+	draws := []struct {
+		name string
+		draw func(r *Rasterizer, emit func(y, xMin int, coverage []float32))
+	}{
+		{"nonzero", func(r *Rasterizer, emit func(y, xMin int, coverage []float32)) {
+			r.FillNonZero(star.Iter(), emit)
+		}},
+		{"evenodd", func(r *Rasterizer, emit func(y, xMin int, coverage []float32)) {
+			r.FillEvenOdd(star.Iter(), emit)
+		}},
+		{"partly outside", func(r *Rasterizer, emit func(y, xMin int, coverage []float32)) {
+			// edges left and right of the clip rectangle
+			r.FillNonZero(rectPath(-20.5, 10.3, 150.2, 100.7).Iter(), emit)
+		}},
+		{"clipped", func(r *Rasterizer, emit func(y, xMin int, coverage []float32)) {
+			r.PushClipPath(starPath(7, size).Iter(), NonZero)
+			r.FillEvenOdd(star.Iter(), emit)
+		}},
+	}
+
+	for _, d := range draws {
+		render := func(sparseRatio float64) []float32 {
+			r := NewRasterizer(rect.Rect{URx: size, URy: size})
+			r.smallPathThreshold = 0
+			r.sparseCellRatio = sparseRatio
+			buf := make([]float32, size*size)
+			lastY := -1
+			d.draw(r, func(y, xMin int, coverage []float32) {
+				if y <= lastY {
+					t.Errorf("%s: row %d emitted after row %d", d.name, y, lastY)
+				}
+				lastY = y
+				copy(buf[y*size+xMin:], coverage)
+			})
+			return buf
+		}
+
+		dense := render(0)
+		sparse := render(math.Inf(1))
+		for i := range dense {
+			if dense[i] != sparse[i] {
+				t.Errorf("%s: pixel (%d,%d) = %g, want %g",
+					d.name, i%size, i/size, sparse[i], dense[i])
+				break
+			}
+		}
+	}
+}
+
+func TestSparseSelection(t *testing.T) {
+	r := NewRasterizer(rect.Rect{URx: 5000, URy: 5000})
+
+	// A thin diagonal stroke across the page touches few pixels.
+	r.Width = 2
+	diagonal := (&path.Data{}).MoveTo(vec.Vec2{X: 10, Y: 10}).LineTo(vec.Vec2{X: 4990, Y: 4990})
+	r.cells = r.cells[:0]
+	r.Stroke(diagonal.Iter(), func(y, xMin int, coverage []float32) {})
+	if len(r.cells) == 0 {
+		t.Error("sparse cells not used for diagonal stroke")
+	}
+
+	// A finely subdivided shape touches most pixels of its bounding box.
+	zigzag := &path.Data{}
+	zigzag.MoveTo(vec.Vec2{X: 0, Y: 0})
+	for i := range 300 {
+		zigzag.LineTo(vec.Vec2{X: 300, Y: float64(i) + 0.5})
+		zigzag.LineTo(vec.Vec2{X: 0, Y: float64(i) + 1})
+	}
+	zigzag.Close()
+	r.cells = r.cells[:0]
+	r.FillNonZero(zigzag.Iter(), func(y, xMin int, coverage []float32) {})
+	if len(r.cells) != 0 {
+		t.Error("sparse cells used for dense shape")
+	}
+}
@@ -19,6 +19,7 @@ package raster
 import (
 	"math"
 
+	"seehuhn.de/go/geom/matrix"
 	"seehuhn.de/go/geom/path"
 	"seehuhn.de/go/geom/vec"
 	"seehuhn.de/go/pdf/graphics"
@@ -35,18 +36,56 @@ type strokeSegment struct {
 // MiterLimit, Dash, and DashPhase. The emit callback receives coverage
 // row-by-row; its slice argument is valid only during the call.
 func (r *Rasterizer) Stroke(p path.Path, emit func(y, xMin int, coverage []float32)) {
+	r.buildStrokeOutlines(p)
+
+	// Fill all stroke polygons together as a compound path
+	r.fillStrokeOutlines(emit)
+}
+
+// buildStrokeOutlines computes the stroke outline polygons for p, in user
+// space. Results are stored in r.stroke and r.strokeOffsets.
+func (r *Rasterizer) buildStrokeOutlines(p path.Path) {
 	// Flatten path into subpaths (results stored in r.segs, etc.)
 	r.flattenPath(p)
-	if len(r.segsOffsets) == 0 && len(r.degeneratePoints) == 0 {
-		return
-	}
+	r.strokeSegments()
+}
 
+// strokeSegments computes the stroke outline polygons, in user space, for
+// the path previously flattened by flattenPath. Results are stored in
+// r.stroke and r.strokeOffsets. For hairlines and stroke adjustment the
+// flattened segments are modified.
+func (r *Rasterizer) strokeSegments() {
 	// Build stroke outlines for all subpaths into a single contiguous buffer.
 	// strokeOffsets tracks where each polygon starts. This ensures overlapping
 	// dash segments are composited correctly using the nonzero winding rule.
 	r.stroke = r.stroke[:0]
 	r.strokeOffsets = r.strokeOffsets[:0]
+	if len(r.segsOffsets) == 0 && len(r.degeneratePoints) == 0 {
+		return
+	}
+
+	dash := r.Dash
+	if len(dash) > 0 && r.tooManyDashes() {
+		// Render absurd dash patterns as solid lines.
+		dash = nil
+	}
 
+	// Apply dash pattern if specified (results stored in r.dashedSegs)
+	dashed := len(dash) > 0
+	if dashed {
+		r.applyDashPattern(dash)
+	}
+
+	if r.Width == 0 || r.adjustStroke() {
+		r.strokeInDeviceSpace(dashed)
+		return
+	}
+	r.strokeFlattened(dashed)
+}
+
+// strokeFlattened builds the stroke outlines from the flattened segments,
+// or from the dashed segments if dashed is set.
+func (r *Rasterizer) strokeFlattened(dashed bool) {
 	// Handle degenerate subpaths (no orientation): only round cap produces circle
 	if r.Cap == graphics.LineCapRound {
 		for _, pt := range r.degeneratePoints {
@@ -56,21 +95,81 @@ func (r *Rasterizer) Stroke(p path.Path, emit func(y, xMin int, coverage []float
 		}
 	}
 
-	// Apply dash pattern if specified
-	if len(r.Dash) > 0 {
+	if dashed {
 		r.strokeDashedSubpaths()
 	} else {
 		r.strokeAllSubpaths()
 	}
+}
 
-	// Fill all stroke polygons together as a compound path
-	r.fillStrokeOutlines(emit)
+// strokeInDeviceSpace builds the outlines for hairlines and for stroke
+// adjustment, where the line width is given in device pixels.
+//
+// A zero width is the thinnest line that can be rendered; here this is a
+// line of width one device pixel, independent of the CTM. With stroke
+// adjustment (see adjustStroke), the device width is rounded to a whole
+// number of pixels and horizontal and vertical segments are snapped to
+// the pixel grid.
+//
+// Flattening and dashing are done in user space as usual; the segments
+// are then transformed to device space and stroked there with the
+// identity CTM. Finally, the outlines are transformed back, so that
+// r.stroke is in user space as for other widths.
+func (r *Rasterizer) strokeInDeviceSpace(dashed bool) {
+	if dashed {
+		r.segmentsToDevice(r.dashedSegs)
+	} else {
+		r.segmentsToDevice(r.segs)
+	}
+	for i, pt := range r.degeneratePoints {
+		r.degeneratePoints[i] = r.toDevice(pt)
+	}
+
+	width := 1.0
+	if r.adjustStroke() {
+		width = r.adjustedWidth()
+		if dashed {
+			snapSegments(r.dashedSegs, r.dashedSegsOffsets, nil, width)
+		} else {
+			snapSegments(r.segs, r.segsOffsets, r.subpathClosed, width)
+		}
+	}
+
+	ctm, userWidth := r.CTM, r.Width
+	r.CTM = matrix.Identity
+	r.Width = width
+	r.strokeFlattened(dashed)
+	r.CTM = ctm
+	r.Width = userWidth
+
+	inv := ctm.Inv()
+	for i, pt := range r.stroke {
+		x, y := inv.Apply(pt.X, pt.Y)
+		r.stroke[i] = vec.Vec2{X: x, Y: y}
+	}
+}
+
+// segmentsToDevice transforms stroke segments from user space to device
+// space, in place. The tangent is transformed rather than recomputed, so
+// that zero-length dash segments keep their orientation.
+func (r *Rasterizer) segmentsToDevice(segs []strokeSegment) {
+	for i := range segs {
+		seg := &segs[i]
+		seg.A = r.toDevice(seg.A)
+		seg.B = r.toDevice(seg.B)
+		t := r.transformLinear(seg.T).Normalize()
+		seg.T = t
+		seg.N = vec.Vec2{X: -t.Y, Y: t.X}
+	}
 }
 
 // strokeAllSubpaths strokes all flattened subpaths (non-dashed case).
 func (r *Rasterizer) strokeAllSubpaths() {
 	numSubpaths := len(r.segsOffsets)
 	for i := range numSubpaths {
+		if r.aborted() {
+			return
+		}
 		segs := r.getSubpathSegments(i)
 		closed := r.subpathClosed[i]
 
@@ -97,13 +196,13 @@ func (r *Rasterizer) getSubpathSegments(i int) []strokeSegment {
 	return r.segs[start:end]
 }
 
-// strokeDashedSubpaths applies dash pattern and strokes the resulting segments.
+// strokeDashedSubpaths strokes the segments produced by applyDashPattern.
 func (r *Rasterizer) strokeDashedSubpaths() {
-	// Apply dash pattern - populates r.dashedSegs and r.dashedSegsOffsets
-	r.applyDashPattern()
-
 	numDashes := len(r.dashedSegsOffsets)
 	for i := range numDashes {
+		if r.aborted() {
+			return
+		}
 		segs := r.getDashedSegments(i)
 
 		// Handle dash-created zero-length segments (have orientation from underlying path)
@@ -165,6 +264,9 @@ func (r *Rasterizer) flattenPath(p path.Path) {
 	sawDrawingCmd := false // tracks if we saw LineTo/QuadTo/CubeTo (for degenerate detection)
 
 	for cmd, pts := range p {
+		if r.aborted() {
+			break
+		}
 		switch cmd {
 		case path.CmdMoveTo:
 			// close previous subpath if needed
@@ -631,6 +733,10 @@ func (r *Rasterizer) addArc(center vec.Vec2, radius float64, startDir vec.Vec2,
 	if angleStep <= 0 || math.IsNaN(angleStep) {
 		angleStep = math.Pi / 4 // fallback
 	}
+	if r.limited && !(float64(len(r.stroke))+absSweep/angleStep < float64(r.limits.MaxEdges)) {
+		r.abort("MaxEdges", r.limits.MaxEdges)
+		return
+	}
 	n := int(math.Ceil(absSweep / angleStep))
 	n = max(n, 1)
 
@@ -667,14 +773,14 @@ func (r *Rasterizer) addSquare(center vec.Vec2, T vec.Vec2, d float64) {
 	)
 }
 
-// applyDashPattern applies the dash pattern to flattened subpaths.
-// Results are stored in r.dashedSegs and r.dashedSegsOffsets.
-func (r *Rasterizer) applyDashPattern() {
+// applyDashPattern applies the given dash pattern, with r.DashPhase, to
+// the flattened subpaths. Results are stored in r.dashedSegs and
+// r.dashedSegsOffsets.
+func (r *Rasterizer) applyDashPattern(dash []float64) {
 	// Clear output buffers (preserving capacity)
 	r.dashedSegs = r.dashedSegs[:0]
 	r.dashedSegsOffsets = r.dashedSegsOffsets[:0]
 
-	dash := r.Dash
 	dashLen := len(dash)
 
 	// Compute total pattern length (doubled for odd-length patterns)
@@ -698,6 +804,9 @@ func (r *Rasterizer) applyDashPattern() {
 
 	numSubpaths := len(r.segsOffsets)
 	for spIdx := range numSubpaths {
+		if r.canceled() {
+			return
+		}
 		segments := r.getSubpathSegments(spIdx)
 		closed := r.subpathClosed[spIdx]
 		if len(segments) == 0 {
@@ -834,27 +943,19 @@ func (r *Rasterizer) fillStrokeOutlines(emit func(y, xMin int, coverage []float3
 	}
 
 	// Collect edges directly from stroke polygons (no intermediate path allocation)
-	xMin, xMax, yMin, yMax, ok := r.collectStrokeEdges()
+	xMin, xMax, yMin, yMax, ok := r.collectStrokeEdges(true)
 	if !ok {
 		return
 	}
 
-	// Choose approach based on bounding box size
-	width := xMax - xMin
-	height := yMax - yMin
-
-	if width*height < r.smallPathThreshold {
-		r.fillSmallPath(xMin, xMax, yMin, yMax, fillNonZero, emit)
-	} else {
-		r.fillLargePath(xMin, xMax, yMin, yMax, fillNonZero, emit)
-	}
+	r.fillEdges(xMin, xMax, yMin, yMax, NonZero, emit)
 }
 
 // collectStrokeEdges builds the edge list directly from stroke polygons.
-// This avoids creating an intermediate path representation.
-func (r *Rasterizer) collectStrokeEdges() (xMin, xMax, yMin, yMax int, ok bool) {
-	r.edges = r.edges[:0]
-	r.edgeBBoxFirst = true
+// This avoids creating an intermediate path representation. The cull
+// argument is as for collectPathEdges.
+func (r *Rasterizer) collectStrokeEdges(cull bool) (xMin, xMax, yMin, yMax int, ok bool) {
+	r.startEdges(cull)
 
 	for i, start := range r.strokeOffsets {
 		// Determine end of this polygon
@@ -881,20 +982,5 @@ func (r *Rasterizer) collectStrokeEdges() (xMin, xMax, yMin, yMax int, ok bool)
 		return 0, 0, 0, 0, false
 	}
 
-	// Clamp to clip bounds and convert to integers
-	clipXMin := int(r.Clip.LLx)
-	clipXMax := int(r.Clip.URx)
-	clipYMin := int(r.Clip.LLy)
-	clipYMax := int(r.Clip.URy)
-
-	xMin = max(int(math.Floor(r.edgeDevXMin)), clipXMin)
-	xMax = min(int(math.Floor(r.edgeDevXMax))+1, clipXMax)
-	yMin = max(int(math.Floor(r.edgeDevYMin)), clipYMin)
-	yMax = min(int(math.Floor(r.edgeDevYMax))+1, clipYMax)
-
-	if xMin >= xMax || yMin >= yMax {
-		return 0, 0, 0, 0, false
-	}
-
-	return xMin, xMax, yMin, yMax, true
+	return r.edgeBounds()
 }
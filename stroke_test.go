@@ -0,0 +1,98 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package raster
+
+import (
+	"math"
+	"testing"
+
+	"seehuhn.de/go/geom/matrix"
+	"seehuhn.de/go/geom/path"
+	"seehuhn.de/go/geom/rect"
+	"seehuhn.de/go/geom/vec"
+)
+
+func TestHairline(t *testing.T) {
+	const size = 40
+
+	// The line is one device pixel wide, for different CTMs.
+	ctms := []matrix.Matrix{
+		matrix.Identity,
+		matrix.Scale(10, 10),
+		matrix.Scale(0.1, 3),
+		matrix.Matrix{0, 2, 5, 0, 0, 0}, // swaps the axes
+	}
+	for _, M := range ctms {
+		inv := M.Inv()
+		x0, y0 := inv.Apply(5, 20.5)
+		x1, y1 := inv.Apply(35, 20.5)
+		p := (&path.Data{}).MoveTo(vec.Vec2{X: x0, Y: y0}).LineTo(vec.Vec2{X: x1, Y: y1})
+
+		r := NewRasterizer(rect.Rect{URx: size, URy: size})
+		r.CTM = M
+		r.Width = 0
+		got := renderStroke(r, p, size, size)
+		for y := range size {
+			for x := range size {
+				want := float32(0)
+				if y == 20 && x >= 5 && x < 35 {
+					want = 1
+				}
+				if c := got[y*size+x]; !closeTo(c, want) {
+					t.Errorf("CTM %v: pixel (%d,%d) = %g, want %g", M, x, y, c, want)
+				}
+			}
+		}
+	}
+}
+
+func TestHairlineDashed(t *testing.T) {
+	const size = 40
+
+	// Dash lengths are in user space; the width is in device space.
+	p := (&path.Data{}).MoveTo(vec.Vec2{X: 2, Y: 5.25}).LineTo(vec.Vec2{X: 18, Y: 5.25})
+	r := NewRasterizer(rect.Rect{URx: size, URy: size})
+	r.CTM = matrix.Scale(2, 2)
+	r.Width = 0
+	r.Dash = []float64{2, 2}
+	got := renderStroke(r, p, size, size)
+	for x := range size {
+		want := float32(0)
+		if x >= 4 && x < 36 && (x-4)%8 < 4 {
+			want = 1
+		}
+		if c := got[10*size+x]; !closeTo(c, want) {
+			t.Errorf("pixel (%d,10) = %g, want %g", x, c, want)
+		}
+	}
+
+	// A diagonal curve covers about one pixel per unit of device length.
+	q := (&path.Data{}).
+		MoveTo(vec.Vec2{X: 2, Y: 2}).
+		CubeTo(vec.Vec2{X: 10, Y: 2}, vec.Vec2{X: 10, Y: 18}, vec.Vec2{X: 18, Y: 18})
+	r.Dash = nil
+	var total float64
+	r.Stroke(q.Iter(), func(y, xMin int, coverage []float32) {
+		for _, c := range coverage {
+			total += float64(c)
+		}
+	})
+	c := newDashCurve(vec.Vec2{X: 4, Y: 4}, vec.Vec2{X: 20, Y: 4}, vec.Vec2{X: 20, Y: 36}, vec.Vec2{X: 36, Y: 36})
+	if L := c.length(); math.Abs(total-L) > 0.02*L {
+		t.Errorf("total coverage %g, curve length %g", total, L)
+	}
+}
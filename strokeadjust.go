@@ -0,0 +1,165 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package raster
+
+import (
+	"math"
+
+	"seehuhn.de/go/geom/vec"
+)
+
+// Stroke adjustment builds the stroke with a circular pen in device space.
+// This only approximates the true outline well for thin strokes and for
+// CTMs which scale all directions by a similar factor; other strokes are
+// drawn without adjustment.
+const (
+	maxAdjustWidth      = 3   // device pixels, along the widest direction
+	maxAdjustAnisotropy = 1.5 // ratio of the largest to the smallest scale factor
+)
+
+// adjustStroke reports whether stroke adjustment is applied to the current
+// stroke: StrokeAdjust must be set, and the stroke must be thin and not
+// strongly distorted by the CTM. Hairlines are always adjusted, since they
+// are one pixel wide in every direction.
+func (r *Rasterizer) adjustStroke() bool {
+	if !r.StrokeAdjust {
+		return false
+	}
+	if r.Width == 0 {
+		return true
+	}
+	sMin, sMax := r.scaleRange()
+	return r.Width*sMax <= maxAdjustWidth && sMax <= maxAdjustAnisotropy*sMin
+}
+
+// scaleRange returns the smallest and largest factor by which the CTM
+// scales lengths, i.e. the singular values of its linear part.
+func (r *Rasterizer) scaleRange() (sMin, sMax float64) {
+	a, b, c, d := r.CTM[0], r.CTM[1], r.CTM[2], r.CTM[3]
+	det := math.Abs(a*d - b*c)
+	sum := a*a + b*b + c*c + d*d
+	sMax = math.Sqrt((sum + math.Sqrt(max(0, sum*sum-4*det*det))) / 2)
+	return det / sMax, sMax
+}
+
+// adjustedWidth returns the device-space line width used for stroke
+// adjustment: the user-space width scaled by the geometric mean of the
+// CTM's scale factors, rounded to a whole number of pixels, and at least
+// one pixel.
+func (r *Rasterizer) adjustedWidth() float64 {
+	det := r.CTM[0]*r.CTM[3] - r.CTM[1]*r.CTM[2]
+	return max(1, math.Round(r.Width*math.Sqrt(math.Abs(det))))
+}
+
+// snapSegments moves the horizontal and vertical segments in segs, which
+// must be in device space, so that a stroke of the given width has its
+// edges on pixel boundaries. offsets gives the start of each subpath in
+// segs, and closed whether each subpath is closed; nil means that all
+// subpaths are open.
+//
+// The snapping is done per vertex, so that consecutive segments stay
+// connected: a vertex is moved vertically if it is an endpoint of a
+// horizontal segment, and horizontally if it is an endpoint of a vertical
+// segment.
+func snapSegments(segs []strokeSegment, offsets []int, closed []bool, width float64) {
+	for i, start := range offsets {
+		end := len(segs)
+		if i+1 < len(offsets) {
+			end = offsets[i+1]
+		}
+		isClosed := closed != nil && closed[i]
+		snapSubpath(segs[start:end], isClosed, width)
+	}
+}
+
+// snapSubpath snaps the vertices of a single subpath. See snapSegments.
+func snapSubpath(segs []strokeSegment, closed bool, width float64) {
+	n := len(segs)
+	if n == 0 {
+		return
+	}
+
+	// Vertex k is the start point of segs[k]. The alignment of each
+	// segment is determined before its start point is moved, so that the
+	// decisions only depend on the original geometry.
+	prevH, prevV := false, false
+	if closed {
+		prevH, prevV = axisAligned(&segs[n-1])
+	}
+	for k := range n {
+		h, v := axisAligned(&segs[k])
+		pt := segs[k].A
+		if prevH || h {
+			pt.Y = snapCoordinate(pt.Y, width)
+		}
+		if prevV || v {
+			pt.X = snapCoordinate(pt.X, width)
+		}
+		segs[k].A = pt
+		if k > 0 {
+			segs[k-1].B = pt
+		}
+		prevH, prevV = h, v
+	}
+
+	// the end point of the subpath
+	if closed {
+		segs[n-1].B = segs[0].A
+	} else {
+		pt := segs[n-1].B
+		if prevH {
+			pt.Y = snapCoordinate(pt.Y, width)
+		}
+		if prevV {
+			pt.X = snapCoordinate(pt.X, width)
+		}
+		segs[n-1].B = pt
+	}
+
+	// update the tangents and normals
+	for i := range segs {
+		seg := &segs[i]
+		d := seg.B.Sub(seg.A)
+		length := d.Length()
+		if length < zeroLengthThreshold {
+			continue // keep the original orientation
+		}
+		seg.T = d.Mul(1 / length)
+		seg.N = vec.Vec2{X: -seg.T.Y, Y: seg.T.X}
+	}
+}
+
+// axisAligned reports whether a device-space segment is horizontal or
+// vertical. For zero-length segments, which occur in dash patterns, the
+// tangent is used.
+func axisAligned(seg *strokeSegment) (horizontal, vertical bool) {
+	d := seg.B.Sub(seg.A)
+	if d.Length() < zeroLengthThreshold {
+		d = seg.T
+	}
+	horizontal = math.Abs(d.Y) < axisAlignedThreshold*math.Abs(d.X)
+	vertical = math.Abs(d.X) < axisAlignedThreshold*math.Abs(d.Y)
+	return horizontal, vertical
+}
+
+// snapCoordinate moves the centre line coordinate c of a stroke of the
+// given width so that both edges of the stroke fall on pixel boundaries.
+// The lower edge is rounded to the nearest integer, with halves rounded
+// up.
+func snapCoordinate(c, width float64) float64 {
+	return math.Floor(c-width/2+0.5) + width/2
+}
@@ -0,0 +1,184 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package raster
+
+import (
+	"math"
+	"testing"
+
+	"seehuhn.de/go/geom/matrix"
+	"seehuhn.de/go/geom/path"
+	"seehuhn.de/go/geom/rect"
+	"seehuhn.de/go/geom/vec"
+)
+
+func TestSnapCoordinate(t *testing.T) {
+	cases := []struct {
+		c, width, want float64
+	}{
+		{10, 1, 10.5},
+		{10.5, 1, 10.5},
+		{10.99, 1, 10.5},
+		{9.99, 1, 9.5},
+		{10, 2, 10},
+		{10.4, 2, 10},
+		{10.6, 2, 11},
+		{10.2, 3, 10.5},
+	}
+	for _, c := range cases {
+		if got := snapCoordinate(c.c, c.width); got != c.want {
+			t.Errorf("snapCoordinate(%g, %g) = %g, want %g", c.c, c.width, got, c.want)
+		}
+	}
+}
+
+// rowCoverage returns the total coverage of each row of buf.
+func rowCoverage(buf []float32, w int) []float64 {
+	rows := make([]float64, len(buf)/w)
+	for i, c := range buf {
+		rows[i/w] += float64(c)
+	}
+	return rows
+}
+
+func TestStrokeAdjust(t *testing.T) {
+	const size = 40
+
+	cases := []struct {
+		ctm   matrix.Matrix
+		width float64
+		y     float64 // user space
+		rows  []int   // fully covered device rows
+	}{
+		{matrix.Identity, 1, 10, []int{10}},
+		{matrix.Identity, 0.2, 10.8, []int{10}},
+		{matrix.Identity, 2.3, 10.1, []int{9, 10}},
+		{matrix.Scale(2, 2), 0.3, 5.1, []int{10}},
+		{matrix.Scale(2, 2), 1.2, 5.1, []int{9, 10}},
+		{matrix.Identity, 0, 20.2, []int{20}}, // hairline
+	}
+	for _, c := range cases {
+		inv := c.ctm.Inv()
+		x0, _ := inv.Apply(5, 0)
+		x1, _ := inv.Apply(35, 0)
+		p := (&path.Data{}).MoveTo(vec.Vec2{X: x0, Y: c.y}).LineTo(vec.Vec2{X: x1, Y: c.y})
+
+		r := NewRasterizer(rect.Rect{URx: size, URy: size})
+		r.CTM = c.ctm
+		r.Width = c.width
+		r.StrokeAdjust = true
+		rows := rowCoverage(renderStroke(r, p, size, size), size)
+		for y, total := range rows {
+			want := 0.0
+			for _, row := range c.rows {
+				if row == y {
+					want = 30
+				}
+			}
+			if math.Abs(total-want) > 1e-4 {
+				t.Errorf("CTM %v, width %g, y=%g: row %d has coverage %g, want %g",
+					c.ctm, c.width, c.y, y, total, want)
+			}
+		}
+	}
+}
+
+func TestStrokeAdjustCorners(t *testing.T) {
+	const size = 40
+
+	// A closed rectangle stays closed, and diagonal segments are not
+	// moved.
+	p := (&path.Data{}).
+		MoveTo(vec.Vec2{X: 5.3, Y: 5.3}).
+		LineTo(vec.Vec2{X: 30.3, Y: 5.3}).
+		LineTo(vec.Vec2{X: 30.3, Y: 20.7}).
+		LineTo(vec.Vec2{X: 5.3, Y: 20.7}).
+		Close().
+		MoveTo(vec.Vec2{X: 5, Y: 25}).
+		LineTo(vec.Vec2{X: 35, Y: 35.5})
+	r := NewRasterizer(rect.Rect{URx: size, URy: size})
+	r.Width = 0.5
+	r.StrokeAdjust = true
+
+	got := renderStroke(r, p, size, size)
+	for y := range 24 {
+		for x := range size {
+			inside := x >= 5 && x <= 30 && y >= 5 && y <= 20
+			onFrame := inside && (x == 5 || x == 30 || y == 5 || y == 20)
+			want := float32(0)
+			if onFrame {
+				want = 1
+			}
+			if c := got[y*size+x]; !closeTo(c, want) {
+				t.Errorf("pixel (%d,%d) = %g, want %g", x, y, c, want)
+			}
+		}
+	}
+
+	// The diagonal line keeps its position and has width one pixel.
+	r.StrokeAdjust = false
+	r.Width = 1
+	diagonal := (&path.Data{}).MoveTo(vec.Vec2{X: 5, Y: 25}).LineTo(vec.Vec2{X: 35, Y: 35.5})
+	want := renderStroke(r, diagonal, size, size)
+	for i := 24 * size; i < len(want); i++ {
+		if !closeTo(got[i], want[i]) {
+			t.Errorf("pixel (%d,%d) = %g, want %g", i%size, i/size, got[i], want[i])
+		}
+	}
+}
+
+func TestStrokeAdjustThick(t *testing.T) {
+	const size = 40
+
+	// Thick strokes, and strokes under strongly anisotropic CTMs, are
+	// drawn without adjustment.
+	cases := []struct {
+		ctm   matrix.Matrix
+		width float64
+	}{
+		{matrix.Identity, 5.3},
+		{matrix.Scale(4, 1), 2},
+		{matrix.Scale(3, 1), 0.8},
+		{matrix.Rotate(0.3).Mul(matrix.Scale(1, 2.5)), 0.6},
+	}
+	for _, c := range cases {
+		inv := c.ctm.Inv()
+		p := &path.Data{}
+		for i, pt := range []vec.Vec2{{X: 5.3, Y: 8.2}, {X: 33.1, Y: 8.2}, {X: 33.1, Y: 30.7}} {
+			x, y := inv.Apply(pt.X, pt.Y)
+			if i == 0 {
+				p.MoveTo(vec.Vec2{X: x, Y: y})
+			} else {
+				p.LineTo(vec.Vec2{X: x, Y: y})
+			}
+		}
+
+		r := NewRasterizer(rect.Rect{URx: size, URy: size})
+		r.CTM = c.ctm
+		r.Width = c.width
+		want := renderStroke(r, p, size, size)
+		r.StrokeAdjust = true
+		got := renderStroke(r, p, size, size)
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("CTM %v, width %g: pixel (%d,%d) = %g, want %g",
+					c.ctm, c.width, i%size, i/size, got[i], want[i])
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package raster
+
+import (
+	"seehuhn.de/go/geom/path"
+	"seehuhn.de/go/geom/vec"
+)
+
+// StrokeToPath returns the outline of the stroked path, in user space,
+// like the PostScript strokepath operator. The outline includes caps,
+// joins and dashes, as determined by Width, Cap, Join, MiterLimit, Dash
+// and DashPhase. Filling the result with the nonzero winding rule covers
+// the same area as Stroke.
+//
+// The outline consists of closed polygons. Curves are flattened using
+// Flatness, measured in device space, so the result depends on CTM.
+func (r *Rasterizer) StrokeToPath(p path.Path) *path.Data {
+	r.buildStrokeOutlines(p)
+	return r.strokeOutlinePath(func(v vec.Vec2) vec.Vec2 { return v })
+}
+
+// StrokeToDevicePath is like StrokeToPath, but returns the outline in
+// device space, i.e. with CTM applied.
+func (r *Rasterizer) StrokeToDevicePath(p path.Path) *path.Data {
+	r.buildStrokeOutlines(p)
+	return r.strokeOutlinePath(r.toDevice)
+}
+
+// strokeOutlinePath converts the polygons in r.stroke to a path, applying
+// the transformation f to all points.
+func (r *Rasterizer) strokeOutlinePath(f func(vec.Vec2) vec.Vec2) *path.Data {
+	res := &path.Data{}
+	for i, start := range r.strokeOffsets {
+		end := len(r.stroke)
+		if i+1 < len(r.strokeOffsets) {
+			end = r.strokeOffsets[i+1]
+		}
+		poly := r.stroke[start:end]
+		if len(poly) < 2 {
+			continue
+		}
+
+		res.MoveTo(f(poly[0]))
+		for _, pt := range poly[1:] {
+			res.LineTo(f(pt))
+		}
+		res.Close()
+	}
+	return res
+}
@@ -0,0 +1,87 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package raster
+
+import (
+	"testing"
+
+	"seehuhn.de/go/geom/matrix"
+	"seehuhn.de/go/geom/path"
+	"seehuhn.de/go/geom/rect"
+	"seehuhn.de/go/geom/vec"
+	"seehuhn.de/go/pdf/graphics"
+)
+
+func TestStrokeToPath(t *testing.T) {
+	const size = 40
+	p := (&path.Data{}).
+		MoveTo(vec.Vec2{X: 5, Y: 5}).
+		LineTo(vec.Vec2{X: 30, Y: 8}).
+		CubeTo(vec.Vec2{X: 40, Y: 20}, vec.Vec2{X: 0, Y: 30}, vec.Vec2{X: 20, Y: 35}).
+		MoveTo(vec.Vec2{X: 30, Y: 30}) // degenerate subpath
+	r := NewRasterizer(rect.Rect{URx: size, URy: size})
+	r.Width = 3
+	r.Cap = graphics.LineCapRound
+	r.Join = graphics.LineJoinMiter
+	r.Dash = []float64{7, 3}
+
+	want := renderStroke(r, p, size, size)
+	outline := r.StrokeToPath(p.Iter())
+	got := renderCoverage(r, outline, size, size)
+	for i := range want {
+		if !closeTo(got[i], want[i]) {
+			t.Errorf("pixel (%d,%d) = %g, want %g", i%size, i/size, got[i], want[i])
+		}
+	}
+
+	// all subpaths are closed polygons
+	for cmd := range outline.Iter() {
+		if cmd == path.CmdQuadTo || cmd == path.CmdCubeTo {
+			t.Fatal("outline contains curves")
+		}
+	}
+	if n := len(outline.Cmds); n == 0 || outline.Cmds[n-1] != path.CmdClose {
+		t.Error("outline is not closed")
+	}
+}
+
+func TestStrokeToDevicePath(t *testing.T) {
+	const size = 40
+	p := (&path.Data{}).
+		MoveTo(vec.Vec2{X: 1, Y: 1}).
+		LineTo(vec.Vec2{X: 8, Y: 3}).
+		LineTo(vec.Vec2{X: 4, Y: 9})
+	r := NewRasterizer(rect.Rect{URx: size, URy: size})
+	r.CTM = matrix.Scale(4, 3)
+	r.Join = graphics.LineJoinRound
+
+	want := renderStroke(r, p, size, size)
+	outline := r.StrokeToDevicePath(p.Iter())
+	r.CTM = matrix.Identity
+	got := renderCoverage(r, outline, size, size)
+	for i := range want {
+		if !closeTo(got[i], want[i]) {
+			t.Errorf("pixel (%d,%d) = %g, want %g", i%size, i/size, got[i], want[i])
+		}
+	}
+
+	// nothing to stroke
+	empty := r.StrokeToPath((&path.Data{}).MoveTo(vec.Vec2{X: 1, Y: 1}).Iter())
+	if len(empty.Cmds) != 0 {
+		t.Errorf("butt-capped point gave %d commands", len(empty.Cmds))
+	}
+}
@@ -0,0 +1,94 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package testcases
+
+import (
+	"image/color"
+	"strings"
+
+	"seehuhn.de/go/geom/rect"
+)
+
+// The blend mode test image consists of vertical stripes of opaque backdrop
+// colours, overlaid by horizontal bands of source colours. Each band is
+// painted with constant alpha BlendAlpha, using the blend mode under test.
+// The bands are inset by one pixel and lie on the pixel grid, so that the
+// result does not depend on the anti-aliasing of the renderer.
+const (
+	// BlendCell is the width of a backdrop stripe and the height of a
+	// source band, in pixels.
+	BlendCell = 4
+
+	// BlendAlpha is the constant alpha (PDF parameter ca) of the sources.
+	BlendAlpha = 0.75
+)
+
+// BlendModes lists the PDF names of the blend modes.
+var BlendModes = []string{
+	"Normal", "Multiply", "Screen", "Overlay",
+	"Darken", "Lighten", "ColorDodge", "ColorBurn",
+	"HardLight", "SoftLight", "Difference", "Exclusion",
+	"Hue", "Saturation", "Color", "Luminosity",
+}
+
+// BlendBackdrops are the colours of the backdrop stripes, from left to
+// right.
+var BlendBackdrops = []color.NRGBA{
+	{0, 0, 0, 255},
+	{255, 255, 255, 255},
+	{128, 128, 128, 255},
+	{230, 40, 40, 255},
+	{40, 200, 80, 255},
+	{30, 60, 220, 255},
+	{250, 220, 30, 255},
+	{20, 110, 120, 255},
+}
+
+// BlendSources are the colours of the source bands, from top to bottom.
+var BlendSources = []color.NRGBA{
+	{255, 255, 255, 255},
+	{0, 0, 0, 255},
+	{100, 100, 100, 255},
+	{255, 140, 0, 255},
+	{0, 200, 230, 255},
+	{200, 30, 160, 255},
+	{250, 180, 200, 255},
+	{110, 120, 30, 255},
+}
+
+// BlendSize returns the size of the blend mode test image in pixels.
+func BlendSize() (width, height int) {
+	return BlendCell * len(BlendBackdrops), BlendCell * len(BlendSources)
+}
+
+// BlendBand returns the rectangle covered by source band i, in device
+// coordinates.
+func BlendBand(i int) rect.Rect {
+	w, _ := BlendSize()
+	return rect.Rect{
+		LLx: 1,
+		LLy: float64(BlendCell*i + 1),
+		URx: float64(w - 1),
+		URy: float64(BlendCell*(i+1) - 1),
+	}
+}
+
+// BlendFileName returns the base name of the reference image for the given
+// blend mode, without extension.
+func BlendFileName(mode string) string {
+	return "blend_" + strings.ToLower(mode)
+}
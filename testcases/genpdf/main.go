@@ -16,10 +16,13 @@
 
 // Command genpdf generates reference images for raster tests.
 // It creates PDFs from test cases and renders them to PNGs using Ghostscript.
+// The PDFs for the blend mode tests of package composite are rendered in
+// colour.
 package main
 
 import (
 	"fmt"
+	stdcolor "image/color"
 	"maps"
 	"os"
 	"os/exec"
@@ -30,7 +33,9 @@ import (
 	"seehuhn.de/go/geom/path"
 	"seehuhn.de/go/pdf"
 	"seehuhn.de/go/pdf/document"
+	"seehuhn.de/go/pdf/graphics"
 	"seehuhn.de/go/pdf/graphics/color"
+	"seehuhn.de/go/pdf/graphics/extgstate"
 	"seehuhn.de/go/raster/testcases"
 )
 
@@ -53,11 +58,26 @@ func main() {
 				panic(fmt.Errorf("%s: %w", name, err))
 			}
 
-			if err := renderPNG(pdfPath, pngPath); err != nil {
+			if err := renderPNG(pdfPath, pngPath, "pnggray"); err != nil {
 				panic(fmt.Errorf("%s: %w", name, err))
 			}
 		}
 	}
+
+	// Blend mode test images
+	for _, mode := range testcases.BlendModes {
+		name := testcases.BlendFileName(mode)
+		pdfPath := filepath.Join(refDir, name+".pdf")
+		pngPath := filepath.Join(refDir, name+".png")
+
+		if err := generateBlendPDF(mode, pdfPath); err != nil {
+			panic(fmt.Errorf("%s: %w", name, err))
+		}
+
+		if err := renderPNG(pdfPath, pngPath, "png16m"); err != nil {
+			panic(fmt.Errorf("%s: %w", name, err))
+		}
+	}
 }
 
 func generatePDF(tc testcases.TestCase, pdfPath string) error {
@@ -100,6 +120,12 @@ func generatePDF(tc testcases.TestCase, pdfPath string) error {
 		if len(op.Dash) > 0 {
 			page.SetLineDash(op.Dash, op.DashPhase)
 		}
+		if op.StrokeAdjust {
+			page.SetExtGState(&extgstate.ExtGState{
+				Set:              graphics.StateStrokeAdjustment,
+				StrokeAdjustment: true,
+			})
+		}
 	}
 
 	// Draw path - convert quadratic to cubic (PDF doesn't support quadratic)
@@ -131,14 +157,58 @@ func generatePDF(tc testcases.TestCase, pdfPath string) error {
 	return page.Close()
 }
 
-func renderPNG(pdfPath, pngPath string) error {
+// generateBlendPDF creates the blend mode test image described in
+// package testcases: opaque backdrop stripes, overlaid by source bands
+// painted with the given blend mode (/BM) and constant alpha (/ca).
+func generateBlendPDF(mode, pdfPath string) error {
+	w, h := testcases.BlendSize()
+	paper := &pdf.Rectangle{
+		URx: float64(w),
+		URy: float64(h),
+	}
+
+	page, err := document.CreateSinglePage(pdfPath, paper, pdf.V1_7, nil)
+	if err != nil {
+		return err
+	}
+
+	// PDF origin is bottom-left; test cases assume top-left.
+	page.Transform(matrix.Matrix{1, 0, 0, -1, 0, float64(h)})
+
+	for i, c := range testcases.BlendBackdrops {
+		page.SetFillColor(deviceRGB(c))
+		page.Rectangle(float64(i*testcases.BlendCell), 0, testcases.BlendCell, float64(h))
+		page.Fill()
+	}
+
+	page.SetExtGState(&extgstate.ExtGState{
+		Set:       graphics.StateBlendMode | graphics.StateFillAlpha,
+		BlendMode: graphics.BlendMode{pdf.Name(mode)},
+		FillAlpha: testcases.BlendAlpha,
+	})
+	for i, c := range testcases.BlendSources {
+		b := testcases.BlendBand(i)
+		page.SetFillColor(deviceRGB(c))
+		page.Rectangle(b.LLx, b.LLy, b.URx-b.LLx, b.URy-b.LLy)
+		page.Fill()
+	}
+
+	return page.Close()
+}
+
+func deviceRGB(c stdcolor.NRGBA) color.DeviceRGB {
+	return color.DeviceRGB{float64(c.R) / 255, float64(c.G) / 255, float64(c.B) / 255}
+}
+
+func renderPNG(pdfPath, pngPath, device string) error {
 	// Render PDF to PNG using Ghostscript
-	// -sDEVICE=pnggray: 8-bit grayscale (matches Cairo FORMAT_A8)
+	// -sDEVICE=pnggray: 8-bit grayscale (matches Cairo FORMAT_A8),
+	//   or png16m for 24-bit RGB
 	// -r72: 72 DPI (1 point = 1 pixel)
 	// -dGraphicsAlphaBits=4: 4x supersampling for anti-aliasing
 	cmd := exec.Command(
 		"gs", "-q",
-		"-sDEVICE=pnggray",
+		"-sDEVICE="+device,
 		"-r72",
 		"-dGraphicsAlphaBits=4",
 		"-o", pngPath,
@@ -76,6 +76,59 @@ var precisionCases = []TestCase{
 		},
 	},
 
+	{
+		Name:   "thin_line_y_integer_adjusted",
+		Path:   horizontalLineAt(5, 10.0, 59),
+		Width:  64,
+		Height: 64,
+		Op: Stroke{
+			Width:        1.0,
+			Cap:          graphics.LineCapButt,
+			Join:         graphics.LineJoinMiter,
+			MiterLimit:   10,
+			StrokeAdjust: true,
+		},
+	},
+	{
+		Name:   "thin_line_y_half_adjusted",
+		Path:   horizontalLineAt(5, 10.5, 59),
+		Width:  64,
+		Height: 64,
+		Op: Stroke{
+			Width:        1.0,
+			Cap:          graphics.LineCapButt,
+			Join:         graphics.LineJoinMiter,
+			MiterLimit:   10,
+			StrokeAdjust: true,
+		},
+	},
+	{
+		Name:   "thin_line_narrow_adjusted",
+		Path:   horizontalLineAt(5, 20.3, 59),
+		Width:  64,
+		Height: 64,
+		Op: Stroke{
+			Width:        0.4,
+			Cap:          graphics.LineCapButt,
+			Join:         graphics.LineJoinMiter,
+			MiterLimit:   10,
+			StrokeAdjust: true,
+		},
+	},
+	{
+		Name:   "thin_rect_adjusted",
+		Path:   offsetRectangle(10, 30, 40, 22, 0.3),
+		Width:  64,
+		Height: 64,
+		Op: Stroke{
+			Width:        0.3,
+			Cap:          graphics.LineCapButt,
+			Join:         graphics.LineJoinMiter,
+			MiterLimit:   10,
+			StrokeAdjust: true,
+		},
+	},
+
 	// Section 6.2: Large Coordinates
 	{
 		Name:   "large_coord_centered",
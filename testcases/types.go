@@ -61,6 +61,8 @@ type Stroke struct {
 	MiterLimit float64                // miter limit
 	Dash       []float64              // dash pattern (nil for solid)
 	DashPhase  float64                // dash phase offset
+
+	StrokeAdjust bool // PDF stroke adjustment (SA)
 }
 
 func (Stroke) isOperation() {}
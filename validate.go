@@ -0,0 +1,285 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package raster
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"seehuhn.de/go/geom/path"
+	"seehuhn.de/go/geom/vec"
+)
+
+// ParameterError reports an invalid field of a Rasterizer.
+type ParameterError struct {
+	// Field is the name of the Rasterizer field, e.g. "Flatness".
+	Field string
+
+	// Reason describes the problem.
+	Reason string
+}
+
+func (e *ParameterError) Error() string {
+	return fmt.Sprintf("raster: invalid %s: %s", e.Field, e.Reason)
+}
+
+// PathError reports a path which cannot be rendered.
+type PathError struct {
+	// Command is the index of the offending path command.
+	Command int
+
+	// Err is ErrNonFinite or ErrTooComplex.
+	Err error
+}
+
+func (e *PathError) Error() string {
+	return fmt.Sprintf("raster: path command %d: %v", e.Command, e.Err)
+}
+
+func (e *PathError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	// ErrNonFinite indicates a path coordinate which is NaN or infinite,
+	// in user space or after transformation to device space.
+	ErrNonFinite = errors.New("non-finite coordinate")
+
+	// ErrTooComplex indicates a path which would be flattened or dashed
+	// into more than maxPathSegments line segments.
+	ErrTooComplex = errors.New("too many segments")
+)
+
+// maxPathSegments is the maximum number of line segments which the
+// error-returning rendering methods accept for a single path, after
+// flattening and dashing.
+const maxPathSegments = 1 << 22
+
+// maxClipCoordinate bounds the coordinates of Clip, so that pixel indices
+// fit into an int on all platforms.
+const maxClipCoordinate = 1 << 30
+
+// Validate checks that all fields of the Rasterizer have valid values,
+// as described in the field documentation. The returned error, if any,
+// is a *ParameterError.
+func (r *Rasterizer) Validate() error {
+	if err := r.validateFill(); err != nil {
+		return err
+	}
+	return r.validateStroke()
+}
+
+// validateFill checks the fields which are used for filling.
+func (r *Rasterizer) validateFill() error {
+	for _, v := range r.CTM {
+		if !isFinite(v) {
+			return &ParameterError{Field: "CTM", Reason: "non-finite entry"}
+		}
+	}
+	det := r.CTM[0]*r.CTM[3] - r.CTM[1]*r.CTM[2]
+	if det == 0 || !isFinite(1/det) {
+		return &ParameterError{Field: "CTM", Reason: "matrix is singular"}
+	}
+
+	for _, v := range []float64{r.Clip.LLx, r.Clip.LLy, r.Clip.URx, r.Clip.URy} {
+		if math.Abs(v) > maxClipCoordinate || math.IsNaN(v) {
+			return &ParameterError{Field: "Clip", Reason: "coordinate out of range"}
+		}
+		if v != math.Floor(v) {
+			return &ParameterError{Field: "Clip", Reason: "coordinates must be integers"}
+		}
+	}
+	if r.Clip.LLx > r.Clip.URx || r.Clip.LLy > r.Clip.URy {
+		return &ParameterError{Field: "Clip", Reason: "lower-left corner above or right of upper-right corner"}
+	}
+
+	if !(r.Flatness > 0) || !isFinite(r.Flatness) {
+		return &ParameterError{Field: "Flatness", Reason: "must be positive and finite"}
+	}
+	return nil
+}
+
+// validateStroke checks the fields which are only used for stroking.
+func (r *Rasterizer) validateStroke() error {
+	if !(r.Width >= 0) || !isFinite(r.Width) {
+		return &ParameterError{Field: "Width", Reason: "must be non-negative and finite"}
+	}
+	if !(r.MiterLimit >= 1) || !isFinite(r.MiterLimit) {
+		return &ParameterError{Field: "MiterLimit", Reason: "must be at least 1 and finite"}
+	}
+	if len(r.Dash) > 0 {
+		positive := false
+		for _, d := range r.Dash {
+			if !(d >= 0) || !isFinite(d) {
+				return &ParameterError{Field: "Dash", Reason: "elements must be non-negative and finite"}
+			}
+			if d > 0 {
+				positive = true
+			}
+		}
+		if !positive {
+			return &ParameterError{Field: "Dash", Reason: "at least one element must be positive"}
+		}
+	}
+	if !isFinite(r.DashPhase) {
+		return &ParameterError{Field: "DashPhase", Reason: "must be finite"}
+	}
+	return nil
+}
+
+// TryFillNonZero is like FillNonZero, but first checks the fields used for
+// filling and the path. If a problem is found, nothing is emitted and a
+// *ParameterError or *PathError is returned. The path is iterated twice.
+func (r *Rasterizer) TryFillNonZero(p path.Path, emit func(y, xMin int, coverage []float32)) error {
+	if err := r.validateFill(); err != nil {
+		return err
+	}
+	if err := r.checkPath(p, false, false, maxPathSegments); err != nil {
+		return err
+	}
+	r.fill(p, NonZero, emit)
+	return nil
+}
+
+// TryFillEvenOdd is like FillEvenOdd, but first checks the fields used for
+// filling and the path, as for TryFillNonZero.
+func (r *Rasterizer) TryFillEvenOdd(p path.Path, emit func(y, xMin int, coverage []float32)) error {
+	if err := r.validateFill(); err != nil {
+		return err
+	}
+	if err := r.checkPath(p, false, false, maxPathSegments); err != nil {
+		return err
+	}
+	r.fill(p, EvenOdd, emit)
+	return nil
+}
+
+// TryStroke is like Stroke, but first checks all fields of the Rasterizer
+// and the path. If a problem is found, nothing is emitted and a
+// *ParameterError or *PathError is returned. The path is iterated twice.
+func (r *Rasterizer) TryStroke(p path.Path, emit func(y, xMin int, coverage []float32)) error {
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	if err := r.checkPath(p, true, true, maxPathSegments); err != nil {
+		return err
+	}
+	r.Stroke(p, emit)
+	return nil
+}
+
+// TryFillStroke is like FillStroke, but first checks all fields of the
+// Rasterizer and the path, as for TryStroke.
+func (r *Rasterizer) TryFillStroke(p path.Path, rule FillRule, emitFill, emitStroke func(y, xMin int, coverage []float32)) error {
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	if err := r.checkPath(p, true, true, maxPathSegments); err != nil {
+		return err
+	}
+	r.FillStroke(p, rule, emitFill, emitStroke)
+	return nil
+}
+
+// checkPath verifies that all coordinates of p are finite in user and
+// device space, and that flattening (and, if dashes is set, dashing)
+// produces at most maxSegments segments. Unless the path is to be stroked,
+// curves outside the clip region count as one segment, since they are not
+// flattened. The fields of r must be valid.
+func (r *Rasterizer) checkPath(p path.Path, stroke, dashes bool, maxSegments float64) error {
+	r.setCull(!stroke)
+
+	var current, start vec.Vec2
+	segments := 0.0
+	length := 0.0 // upper bound for the user-space length, for dashing
+
+	// The loop is left with break, since a return statement inside the
+	// loop body would make the result escape to the heap.
+	var err error
+	i := 0
+	for cmd, pts := range p {
+		for _, pt := range pts {
+			dx, dy := r.CTM.Apply(pt.X, pt.Y)
+			if !isFinite(pt.X) || !isFinite(pt.Y) || !isFinite(dx) || !isFinite(dy) {
+				err = ErrNonFinite
+			}
+		}
+		if err != nil {
+			break
+		}
+
+		switch cmd {
+		case path.CmdMoveTo:
+			current = pts[0]
+			start = current
+		case path.CmdLineTo:
+			segments++
+			length += pts[0].Sub(current).Length()
+			current = pts[0]
+		case path.CmdQuadTo:
+			if r.cull && r.hullOutside(current, pts[0], pts[1], pts[1]) {
+				segments++
+			} else {
+				segments += r.quadraticSegments(current, pts[0], pts[1])
+			}
+			length += pts[0].Sub(current).Length() + pts[1].Sub(pts[0]).Length()
+			current = pts[1]
+		case path.CmdCubeTo:
+			if r.cull && r.hullOutside(current, pts[0], pts[1], pts[2]) {
+				segments++
+			} else {
+				segments += r.cubicSegments(current, pts[0], pts[1], pts[2])
+			}
+			length += pts[0].Sub(current).Length() + pts[1].Sub(pts[0]).Length() +
+				pts[2].Sub(pts[1]).Length()
+			current = pts[2]
+		case path.CmdClose:
+			segments++
+			length += start.Sub(current).Length()
+			current = start
+		}
+
+		if dashes && len(r.Dash) > 0 {
+			segments += r.dashSegments(length)
+			length = 0
+		}
+		if !(segments <= maxSegments) {
+			err = ErrTooComplex
+			break
+		}
+		i++
+	}
+	if err != nil {
+		return &PathError{Command: i, Err: err}
+	}
+	return nil
+}
+
+// dashSegments returns an upper bound for the number of dashes along a
+// path of the given user-space length.
+func (r *Rasterizer) dashSegments(length float64) float64 {
+	patternLen := 0.0
+	for _, d := range r.Dash {
+		patternLen += d
+	}
+	return math.Ceil(length/patternLen) * float64(len(r.Dash))
+}
+
+func isFinite(x float64) bool {
+	return !math.IsNaN(x) && !math.IsInf(x, 0)
+}
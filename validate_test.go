@@ -0,0 +1,150 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package raster
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"seehuhn.de/go/geom/matrix"
+	"seehuhn.de/go/geom/path"
+	"seehuhn.de/go/geom/rect"
+	"seehuhn.de/go/geom/vec"
+)
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		field  string
+		modify func(r *Rasterizer)
+	}{
+		{"", func(r *Rasterizer) {}},
+		{"", func(r *Rasterizer) { r.Width = 0 }},
+		{"CTM", func(r *Rasterizer) { r.CTM = matrix.Matrix{1, 2, 2, 4, 0, 0} }},
+		{"CTM", func(r *Rasterizer) { r.CTM[4] = math.NaN() }},
+		{"Clip", func(r *Rasterizer) { r.Clip.URx = 10.5 }},
+		{"Clip", func(r *Rasterizer) { r.Clip.LLy = 20 }},
+		{"Clip", func(r *Rasterizer) { r.Clip.URx = math.Inf(1) }},
+		{"Flatness", func(r *Rasterizer) { r.Flatness = 0 }},
+		{"Flatness", func(r *Rasterizer) { r.Flatness = math.NaN() }},
+		{"Width", func(r *Rasterizer) { r.Width = -1 }},
+		{"MiterLimit", func(r *Rasterizer) { r.MiterLimit = 0.5 }},
+		{"Dash", func(r *Rasterizer) { r.Dash = []float64{0, 0} }},
+		{"Dash", func(r *Rasterizer) { r.Dash = []float64{1, -1} }},
+		{"Dash", func(r *Rasterizer) { r.Dash = []float64{1, math.Inf(1)} }},
+		{"DashPhase", func(r *Rasterizer) { r.DashPhase = math.NaN() }},
+	}
+	for _, tc := range cases {
+		r := NewRasterizer(rect.Rect{URx: 10, URy: 10})
+		tc.modify(r)
+		err := r.Validate()
+
+		if tc.field == "" {
+			if err != nil {
+				t.Errorf("default settings: unexpected error %v", err)
+			}
+			continue
+		}
+		var pErr *ParameterError
+		if !errors.As(err, &pErr) || pErr.Field != tc.field {
+			t.Errorf("%s: got error %v", tc.field, err)
+		}
+	}
+}
+
+func TestTryFill(t *testing.T) {
+	r := NewRasterizer(rect.Rect{URx: 10, URy: 10})
+	emitted := false
+	emit := func(y, xMin int, coverage []float32) { emitted = true }
+
+	if err := r.TryFillNonZero(rectPath(1, 1, 5, 5).Iter(), emit); err != nil || !emitted {
+		t.Errorf("valid path: err=%v, emitted=%t", err, emitted)
+	}
+
+	// stroke parameters are not checked for fills
+	r.Width = 0
+	r.Dash = []float64{0}
+	if err := r.TryFillEvenOdd(rectPath(1, 1, 5, 5).Iter(), emit); err != nil {
+		t.Errorf("stroke parameters checked for fill: %v", err)
+	}
+
+	emitted = false
+	r.Flatness = 0
+	err := r.TryFillNonZero(rectPath(1, 1, 5, 5).Iter(), emit)
+	var pErr *ParameterError
+	if !errors.As(err, &pErr) || emitted {
+		t.Errorf("zero flatness: err=%v, emitted=%t", err, emitted)
+	}
+}
+
+func TestTryFillPathErrors(t *testing.T) {
+	r := NewRasterizer(rect.Rect{URx: 10, URy: 10})
+	emit := func(y, xMin int, coverage []float32) {
+		t.Error("invalid path emitted coverage")
+	}
+
+	p := (&path.Data{}).
+		MoveTo(vec.Vec2{X: 0, Y: 0}).
+		LineTo(vec.Vec2{X: 5, Y: 0}).
+		LineTo(vec.Vec2{X: 5, Y: math.NaN()}).
+		Close()
+	err := r.TryFillNonZero(p.Iter(), emit)
+	var pathErr *PathError
+	if !errors.As(err, &pathErr) || pathErr.Command != 2 || !errors.Is(err, ErrNonFinite) {
+		t.Errorf("NaN coordinate: got %v", err)
+	}
+
+	// finite in user space, but not in device space
+	r.CTM = matrix.Scale(1e300, 1e300)
+	p = rectPath(0, 0, 1e10, 1)
+	if err := r.TryFillEvenOdd(p.Iter(), emit); !errors.Is(err, ErrNonFinite) {
+		t.Errorf("overflow: got %v", err)
+	}
+
+	// a huge curve with the default flatness needs too many segments
+	r.CTM = matrix.Identity
+	p = (&path.Data{}).
+		MoveTo(vec.Vec2{X: 0, Y: 0}).
+		CubeTo(vec.Vec2{X: 1e15, Y: 0}, vec.Vec2{X: -1e15, Y: 5}, vec.Vec2{X: 5, Y: 5}).
+		Close()
+	if err := r.TryFillNonZero(p.Iter(), emit); !errors.Is(err, ErrTooComplex) {
+		t.Errorf("huge curve: got %v", err)
+	}
+}
+
+func TestTryStroke(t *testing.T) {
+	r := NewRasterizer(rect.Rect{URx: 10, URy: 10})
+	line := (&path.Data{}).MoveTo(vec.Vec2{X: 1, Y: 5}).LineTo(vec.Vec2{X: 9, Y: 5})
+
+	emitted := false
+	emit := func(y, xMin int, coverage []float32) { emitted = true }
+	if err := r.TryStroke(line.Iter(), emit); err != nil || !emitted {
+		t.Errorf("valid stroke: err=%v, emitted=%t", err, emitted)
+	}
+
+	r.Dash = []float64{0, 0}
+	if err := r.TryStroke(line.Iter(), emit); err == nil {
+		t.Errorf("all-zero dash array accepted")
+	}
+
+	// tiny dashes along a long path
+	r.Dash = []float64{1e-6}
+	long := (&path.Data{}).MoveTo(vec.Vec2{X: 0, Y: 5}).LineTo(vec.Vec2{X: 1e6, Y: 5})
+	if err := r.TryStroke(long.Iter(), emit); !errors.Is(err, ErrTooComplex) {
+		t.Errorf("tiny dashes: got %v", err)
+	}
+}
@@ -0,0 +1,299 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package vector provides a drop-in replacement for the Rasterizer type
+// from golang.org/x/image/vector, backed by a raster.Rasterizer.
+//
+// Code using golang.org/x/image/vector can switch to this package by
+// changing the import path. In addition to the original methods, the
+// Rasterizer supports the even-odd fill rule and stroking:
+//
+//	z := vector.NewRasterizer(w, h)
+//	z.MoveTo(10, 10)
+//	z.LineTo(90, 50)
+//	z.Raster().Width = 4
+//	z.DrawStroke(dst, dst.Bounds(), image.Black, image.Point{})
+//
+// Paths are implicitly closed when filled, as in PDF.
+package vector
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"seehuhn.de/go/geom/path"
+	"seehuhn.de/go/geom/rect"
+	"seehuhn.de/go/geom/vec"
+
+	"seehuhn.de/go/raster"
+)
+
+// Rasterizer is a 2-D vector graphics rasterizer, with the same methods as
+// the Rasterizer from golang.org/x/image/vector.
+//
+// The zero value is usable, in that it is a Rasterizer whose rendered mask
+// image has zero width and zero height. Call Reset to change its bounds.
+type Rasterizer struct {
+	// DrawOp is the operator used for the Draw method.
+	//
+	// The zero value is draw.Over.
+	DrawOp draw.Op
+
+	// FillRule selects the rule used by Draw. The zero value is the
+	// nonzero winding rule.
+	FillRule raster.FillRule
+
+	size       image.Point
+	first, pen vec.Vec2
+	path       path.Data
+	inSubpath  bool // whether segments can be added without a MoveTo
+	r          *raster.Rasterizer
+	mask       []float32 // coverage, size.X × size.Y
+}
+
+// NewRasterizer returns a new Rasterizer whose rendered mask image is bounded
+// by the given width and height.
+func NewRasterizer(w, h int) *Rasterizer {
+	z := &Rasterizer{}
+	z.Reset(w, h)
+	return z
+}
+
+// Reset resets a Rasterizer as if it was just returned by NewRasterizer.
+//
+// This includes setting z.DrawOp to draw.Over and z.FillRule to
+// raster.NonZero. The settings of the underlying raster.Rasterizer are
+// reset to their default values.
+func (z *Rasterizer) Reset(w, h int) {
+	z.size = image.Point{X: w, Y: h}
+	z.first = vec.Vec2{}
+	z.pen = vec.Vec2{}
+	z.path.Cmds = z.path.Cmds[:0]
+	z.path.Coords = z.path.Coords[:0]
+	z.inSubpath = false
+	z.DrawOp = draw.Over
+	z.FillRule = raster.NonZero
+
+	clip := rect.Rect{URx: float64(w), URy: float64(h)}
+	if z.r == nil {
+		z.r = raster.NewRasterizer(clip)
+	} else {
+		*z.r = *raster.NewRasterizer(clip)
+	}
+}
+
+// Raster returns the underlying raster.Rasterizer. Its fields can be used
+// to configure stroking, flattening and the transformation applied to the
+// path coordinates. The Clip field is maintained by Reset.
+func (z *Rasterizer) Raster() *raster.Rasterizer {
+	if z.r == nil {
+		z.r = raster.NewRasterizer(rect.Rect{URx: float64(z.size.X), URy: float64(z.size.Y)})
+	}
+	return z.r
+}
+
+// Size returns the width and height passed to NewRasterizer or Reset.
+func (z *Rasterizer) Size() image.Point {
+	return z.size
+}
+
+// Bounds returns the rectangle from (0, 0) to the width and height passed to
+// NewRasterizer or Reset.
+func (z *Rasterizer) Bounds() image.Rectangle {
+	return image.Rectangle{Max: z.size}
+}
+
+// Pen returns the location of the path-drawing pen: the last argument to the
+// most recent XxxTo call.
+func (z *Rasterizer) Pen() (x, y float32) {
+	return float32(z.pen.X), float32(z.pen.Y)
+}
+
+// ClosePath closes the current path.
+func (z *Rasterizer) ClosePath() {
+	if !z.inSubpath {
+		return
+	}
+	z.path.Close()
+	z.pen = z.first
+	z.inSubpath = false
+}
+
+// MoveTo starts a new path and moves the pen to (ax, ay).
+//
+// The coordinates are allowed to be out of the Rasterizer's bounds.
+func (z *Rasterizer) MoveTo(ax, ay float32) {
+	z.pen = vec.Vec2{X: float64(ax), Y: float64(ay)}
+	z.first = z.pen
+	z.path.MoveTo(z.pen)
+	z.inSubpath = true
+}
+
+// LineTo adds a line segment, from the pen to (bx, by), and moves the pen to
+// (bx, by).
+//
+// The coordinates are allowed to be out of the Rasterizer's bounds.
+func (z *Rasterizer) LineTo(bx, by float32) {
+	z.startSegment()
+	z.pen = vec.Vec2{X: float64(bx), Y: float64(by)}
+	z.path.LineTo(z.pen)
+}
+
+// QuadTo adds a quadratic Bézier segment, from the pen via (bx, by) to (cx,
+// cy), and moves the pen to (cx, cy).
+//
+// The coordinates are allowed to be out of the Rasterizer's bounds.
+func (z *Rasterizer) QuadTo(bx, by, cx, cy float32) {
+	z.startSegment()
+	z.pen = vec.Vec2{X: float64(cx), Y: float64(cy)}
+	z.path.QuadTo(vec.Vec2{X: float64(bx), Y: float64(by)}, z.pen)
+}
+
+// CubeTo adds a cubic Bézier segment, from the pen via (bx, by) and (cx, cy)
+// to (dx, dy), and moves the pen to (dx, dy).
+//
+// The coordinates are allowed to be out of the Rasterizer's bounds.
+func (z *Rasterizer) CubeTo(bx, by, cx, cy, dx, dy float32) {
+	z.startSegment()
+	z.pen = vec.Vec2{X: float64(dx), Y: float64(dy)}
+	z.path.CubeTo(
+		vec.Vec2{X: float64(bx), Y: float64(by)},
+		vec.Vec2{X: float64(cx), Y: float64(cy)},
+		z.pen)
+}
+
+// startSegment begins a new subpath at the pen position, if needed. This
+// allows segments to follow ClosePath or Reset without a MoveTo, as in
+// golang.org/x/image/vector.
+func (z *Rasterizer) startSegment() {
+	if !z.inSubpath {
+		z.path.MoveTo(z.pen)
+		z.first = z.pen
+		z.inSubpath = true
+	}
+}
+
+// Draw implements the Drawer interface from the standard library's image/draw
+// package.
+//
+// The vector paths previously added via the XxxTo calls become the mask for
+// drawing src onto dst. The paths are filled using FillRule. The mask pixel
+// (0, 0) is aligned with r.Min in dst and with sp in src.
+func (z *Rasterizer) Draw(dst draw.Image, r image.Rectangle, src image.Image, sp image.Point) {
+	z.render(func(emit func(y, xMin int, coverage []float32)) {
+		if z.FillRule == raster.EvenOdd {
+			z.r.FillEvenOdd(z.path.Iter(), emit)
+		} else {
+			z.r.FillNonZero(z.path.Iter(), emit)
+		}
+	})
+	z.composite(dst, r, src, sp)
+}
+
+// DrawStroke is like Draw, but strokes the paths instead of filling them.
+// The stroke parameters are taken from the fields of Raster().
+func (z *Rasterizer) DrawStroke(dst draw.Image, r image.Rectangle, src image.Image, sp image.Point) {
+	z.render(func(emit func(y, xMin int, coverage []float32)) {
+		z.r.Stroke(z.path.Iter(), emit)
+	})
+	z.composite(dst, r, src, sp)
+}
+
+// render runs draw and stores the resulting coverage in z.mask.
+func (z *Rasterizer) render(draw func(emit func(y, xMin int, coverage []float32))) {
+	z.Raster() // make sure z.r is set
+	w, h := z.size.X, z.size.Y
+	if cap(z.mask) < w*h {
+		z.mask = make([]float32, w*h)
+	}
+	z.mask = z.mask[:w*h]
+	clear(z.mask)
+
+	draw(func(y, xMin int, coverage []float32) {
+		copy(z.mask[y*w+xMin:], coverage)
+	})
+}
+
+// maskAt returns the mask value of pixel (x, y) in the range [0, 0xffff].
+func (z *Rasterizer) maskAt(x, y int) uint32 {
+	return uint32(z.mask[y*z.size.X+x]*0xffff + 0.5)
+}
+
+// composite draws src onto dst through z.mask, using z.DrawOp.
+func (z *Rasterizer) composite(dst draw.Image, r image.Rectangle, src image.Image, sp image.Point) {
+	w := min(r.Dx(), z.size.X)
+	h := min(r.Dy(), z.size.Y)
+
+	if src, ok := src.(*image.Uniform); ok {
+		sr, sg, sb, sa := src.RGBA()
+		if dst, ok := dst.(*image.RGBA); ok {
+			for y := range h {
+				i := dst.PixOffset(r.Min.X, r.Min.Y+y)
+				for x := range w {
+					ma := z.maskAt(x, y)
+					pix := dst.Pix[i : i+4 : i+4]
+					i += 4
+					if z.DrawOp == draw.Over {
+						if ma == 0 {
+							continue
+						}
+						a := 0xffff - (sa * ma / 0xffff)
+						pix[0] = uint8(((uint32(pix[0])*0x101*a + sr*ma) / 0xffff) >> 8)
+						pix[1] = uint8(((uint32(pix[1])*0x101*a + sg*ma) / 0xffff) >> 8)
+						pix[2] = uint8(((uint32(pix[2])*0x101*a + sb*ma) / 0xffff) >> 8)
+						pix[3] = uint8(((uint32(pix[3])*0x101*a + sa*ma) / 0xffff) >> 8)
+					} else {
+						pix[0] = uint8((sr * ma / 0xffff) >> 8)
+						pix[1] = uint8((sg * ma / 0xffff) >> 8)
+						pix[2] = uint8((sb * ma / 0xffff) >> 8)
+						pix[3] = uint8((sa * ma / 0xffff) >> 8)
+					}
+				}
+			}
+			return
+		}
+	}
+
+	out := color.RGBA64{}
+	outc := color.Color(&out)
+	for y := range h {
+		for x := range w {
+			ma := z.maskAt(x, y)
+			if ma == 0 && z.DrawOp == draw.Over {
+				continue
+			}
+			sr, sg, sb, sa := src.At(sp.X+x, sp.Y+y).RGBA()
+
+			// This algorithm comes from the standard library's image/draw
+			// package.
+			if z.DrawOp == draw.Over {
+				dr, dg, db, da := dst.At(r.Min.X+x, r.Min.Y+y).RGBA()
+				a := 0xffff - (sa * ma / 0xffff)
+				out.R = uint16((dr*a + sr*ma) / 0xffff)
+				out.G = uint16((dg*a + sg*ma) / 0xffff)
+				out.B = uint16((db*a + sb*ma) / 0xffff)
+				out.A = uint16((da*a + sa*ma) / 0xffff)
+			} else {
+				out.R = uint16(sr * ma / 0xffff)
+				out.G = uint16(sg * ma / 0xffff)
+				out.B = uint16(sb * ma / 0xffff)
+				out.A = uint16(sa * ma / 0xffff)
+			}
+			dst.Set(r.Min.X+x, r.Min.Y+y, outc)
+		}
+	}
+}
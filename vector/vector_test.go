@@ -0,0 +1,189 @@
+// seehuhn.de/go/raster - a 2D rendering library
+// Copyright (C) 2026  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package vector
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	xvector "golang.org/x/image/vector"
+
+	"seehuhn.de/go/raster"
+)
+
+// pathBuilder is the method set shared with golang.org/x/image/vector.
+type pathBuilder interface {
+	MoveTo(ax, ay float32)
+	LineTo(bx, by float32)
+	QuadTo(bx, by, cx, cy float32)
+	CubeTo(bx, by, cx, cy, dx, dy float32)
+	ClosePath()
+	Draw(dst draw.Image, r image.Rectangle, src image.Image, sp image.Point)
+}
+
+// addPolygon adds a closed polygon. Curves are not used here, since
+// x/image/vector flattens them more coarsely.
+func addPolygon(z pathBuilder) {
+	z.MoveTo(5, 5)
+	z.LineTo(50, 8)
+	z.LineTo(60.5, 30)
+	z.LineTo(45, 55.25)
+	z.LineTo(12.5, 25)
+	z.ClosePath()
+}
+
+// addCurves adds a closed shape with curved segments.
+func addCurves(z pathBuilder) {
+	z.MoveTo(5, 5)
+	z.LineTo(50, 8)
+	z.QuadTo(60, 30, 45, 55)
+	z.CubeTo(30, 60, 10, 40, 12.5, 25)
+	z.ClosePath()
+}
+
+func TestCompareWithXImage(t *testing.T) {
+	const w, h = 64, 64
+
+	render := func(z pathBuilder, add func(pathBuilder)) *image.Alpha {
+		img := image.NewAlpha(image.Rect(0, 0, w, h))
+		add(z)
+		z.Draw(img, img.Bounds(), image.Opaque, image.Point{})
+		return img
+	}
+
+	want := render(xvector.NewRasterizer(w, h), addPolygon)
+	got := render(NewRasterizer(w, h), addPolygon)
+	for i := range want.Pix {
+		// x/image/vector uses fixed-point arithmetic for small images
+		d := int(got.Pix[i]) - int(want.Pix[i])
+		if d < -4 || d > 4 {
+			t.Errorf("pixel (%d,%d) = %d, x/image/vector gives %d",
+				i%w, i/w, got.Pix[i], want.Pix[i])
+		}
+	}
+
+	// With curves, the pixels differ along the edges but the total area
+	// is nearly the same.
+	want = render(xvector.NewRasterizer(w, h), addCurves)
+	got = render(NewRasterizer(w, h), addCurves)
+	var areaWant, areaGot int
+	for i := range want.Pix {
+		areaWant += int(want.Pix[i])
+		areaGot += int(got.Pix[i])
+	}
+	if d := float64(areaGot-areaWant) / float64(areaWant); d < -0.01 || d > 0.01 {
+		t.Errorf("area %d, x/image/vector gives %d", areaGot, areaWant)
+	}
+}
+
+func TestDrawRGBA(t *testing.T) {
+	red := color.RGBA{R: 255, A: 255}
+	bg := color.RGBA{B: 255, A: 255}
+
+	for _, op := range []draw.Op{draw.Over, draw.Src} {
+		// both the fast path and the generic path
+		for _, src := range []image.Image{image.NewUniform(red), &solid{red}} {
+			dst := image.NewRGBA(image.Rect(0, 0, 20, 20))
+			draw.Draw(dst, dst.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+
+			z := NewRasterizer(10, 10)
+			z.DrawOp = op
+			z.MoveTo(0, 0)
+			z.LineTo(5, 0)
+			z.LineTo(5, 10)
+			z.LineTo(0, 10)
+			z.ClosePath()
+			z.Draw(dst, image.Rect(5, 5, 15, 15), src, image.Point{})
+
+			if c := dst.RGBAAt(7, 7); c != red {
+				t.Errorf("op %v: inside = %v", op, c)
+			}
+			outside := bg
+			if op == draw.Src {
+				outside = color.RGBA{}
+			}
+			if c := dst.RGBAAt(12, 7); c != outside {
+				t.Errorf("op %v: outside the shape = %v, want %v", op, c, outside)
+			}
+			if c := dst.RGBAAt(2, 2); c != bg {
+				t.Errorf("op %v: outside r = %v", op, c)
+			}
+		}
+	}
+}
+
+// solid is a uniform image which is not an *image.Uniform.
+type solid struct{ c color.Color }
+
+func (s *solid) ColorModel() color.Model { return color.RGBAModel }
+func (s *solid) Bounds() image.Rectangle { return image.Rect(-1e9, -1e9, 1e9, 1e9) }
+func (s *solid) At(x, y int) color.Color { return s.c }
+
+func TestEvenOddAndStroke(t *testing.T) {
+	// two nested squares with the same orientation
+	z := NewRasterizer(20, 20)
+	for _, d := range []float32{2, 6} {
+		z.MoveTo(d, d)
+		z.LineTo(20-d, d)
+		z.LineTo(20-d, 20-d)
+		z.LineTo(d, 20-d)
+		z.ClosePath()
+	}
+
+	dst := image.NewAlpha(image.Rect(0, 0, 20, 20))
+	z.Draw(dst, dst.Bounds(), image.Opaque, image.Point{})
+	if dst.AlphaAt(10, 10).A != 255 {
+		t.Error("nonzero: centre not filled")
+	}
+
+	dst = image.NewAlpha(image.Rect(0, 0, 20, 20))
+	z.FillRule = raster.EvenOdd
+	z.Draw(dst, dst.Bounds(), image.Opaque, image.Point{})
+	if dst.AlphaAt(10, 10).A != 0 || dst.AlphaAt(4, 10).A != 255 {
+		t.Error("even-odd: wrong fill")
+	}
+
+	dst = image.NewAlpha(image.Rect(0, 0, 20, 20))
+	z.Raster().Width = 2
+	z.DrawStroke(dst, dst.Bounds(), image.Opaque, image.Point{})
+	if dst.AlphaAt(2, 10).A != 255 || dst.AlphaAt(4, 10).A != 0 || dst.AlphaAt(10, 10).A != 0 {
+		t.Error("stroke: wrong coverage")
+	}
+}
+
+func TestZeroValue(t *testing.T) {
+	var z Rasterizer
+	z.LineTo(1, 1) // no MoveTo
+	dst := image.NewAlpha(image.Rect(0, 0, 4, 4))
+	z.Draw(dst, dst.Bounds(), image.Opaque, image.Point{})
+
+	z.Reset(4, 4)
+	z.LineTo(4, 0) // starts at the pen, (0, 0)
+	z.LineTo(4, 4)
+	z.LineTo(0, 4)
+	z.Draw(dst, dst.Bounds(), image.Opaque, image.Point{})
+	for i, a := range dst.Pix {
+		if a != 255 {
+			t.Fatalf("pixel %d = %d, want 255", i, a)
+		}
+	}
+	if x, y := z.Pen(); x != 0 || y != 4 {
+		t.Errorf("pen at (%g,%g), want (0,4)", x, y)
+	}
+}